@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vaitekunas/version/semver"
+)
+
+// changelogHeader starts a new Keep-a-Changelog file
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// ChangelogOptions holds the parameters of a changelog
+type ChangelogOptions struct {
+	From    string // lower version (excluded), defaults to the version preceding To
+	To      string // upper version (included), defaults to HEAD's version or its unreleased changes
+	Prepend string // Keep-a-Changelog file the section is prepended to (optional)
+}
+
+// Changelog holds the changes between two versions, grouped by type
+type Changelog struct {
+	Version  *Version // nil for unreleased changes
+	Previous *Version // nil if the changelog starts with the first commit
+	Breaking []*ChangelogEntry
+	Features []*ChangelogEntry
+	Fixes    []*ChangelogEntry
+	Other    []*ChangelogEntry
+}
+
+// ChangelogEntry is a single change of a changelog
+type ChangelogEntry struct {
+	Commit       *Commit
+	Conventional *ConventionalCommit // nil if the commit does not follow the convention
+}
+
+// NewChangelog collects the commits between two versions of a repository
+func NewChangelog(repo Repository, opts *ChangelogOptions) (*Changelog, error) {
+
+	versions, err := GetVersions(repo, TieBreakNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine versions: %s", err.Error())
+	}
+
+	changelog := &Changelog{}
+
+	// Upper bound: the version on HEAD, if there is one. Versions of
+	// monorepo components are only compared within their component
+	rev := "HEAD"
+	series := versions.Component("")
+	if opts.To != "" {
+		if changelog.Version = versions.Find(opts.To); changelog.Version == nil {
+			return nil, fmt.Errorf("unknown version '%s'", opts.To)
+		}
+		rev = changelog.Version.Commit
+		series = versions.Component(changelog.Version.Component)
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("could not get last commit: %s", err.Error())
+		}
+		for _, version := range series.versions {
+			if version.Commit == head.Hash {
+				changelog.Version = version
+				break
+			}
+		}
+	}
+
+	// Lower bound
+	if opts.From != "" {
+		if changelog.Previous = versions.Find(opts.From); changelog.Previous == nil {
+			return nil, fmt.Errorf("unknown version '%s'", opts.From)
+		}
+		if changelog.Version != nil && semver.Compare(&changelog.Previous.Version, &changelog.Version.Version) >= 0 {
+			return nil, fmt.Errorf("version %s is not lower than %s", changelog.Previous.String(), changelog.Version.String())
+		}
+	} else {
+		changelog.Previous = series.previous(changelog.Version)
+	}
+
+	from := ""
+	if changelog.Previous != nil {
+		from = changelog.Previous.Commit
+	}
+
+	// Commits of path scheme components are limited to their directory,
+	// just like automatic increases
+	var paths []string
+	if v := changelog.Version; v != nil && !strings.HasPrefix(v.Tag, v.Component+"@") {
+		paths = componentPaths(v.Component, TagSchemePath)
+	}
+
+	commits, err := repo.Commits(from, rev, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits: %s", err.Error())
+	}
+
+	for _, commit := range commits {
+		changelog.add(commit)
+	}
+
+	return changelog, nil
+}
+
+// add adds a commit to the group of its conventional commit type
+func (c *Changelog) add(commit *Commit) {
+	entry := &ChangelogEntry{Commit: commit, Conventional: ParseConventionalCommit(commit)}
+	switch {
+	case entry.Conventional != nil && entry.Conventional.Breaking:
+		c.Breaking = append(c.Breaking, entry)
+	case entry.Conventional != nil && entry.Conventional.Level() == LevelMinor:
+		c.Features = append(c.Features, entry)
+	case entry.Conventional != nil && entry.Conventional.Level() == LevelPatch:
+		c.Fixes = append(c.Fixes, entry)
+	default:
+		c.Other = append(c.Other, entry)
+	}
+}
+
+// changelogGroup is a titled group of changes
+type changelogGroup struct {
+	title   string
+	entries []*ChangelogEntry
+}
+
+// groups returns the non-empty groups of changes, most significant first
+func (c *Changelog) groups() []changelogGroup {
+	groups := []changelogGroup{}
+	for _, group := range []changelogGroup{
+		{"Breaking changes", c.Breaking},
+		{"Features", c.Features},
+		{"Fixes", c.Fixes},
+		{"Other", c.Other},
+	} {
+		if len(group.entries) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// Heading returns the Keep-a-Changelog heading of the changelog section
+func (c *Changelog) Heading() string {
+
+	if c.Version == nil {
+		return "## [Unreleased]"
+	}
+
+	date := c.Version.TagDate
+	if date.IsZero() {
+		date = c.Version.Date
+	}
+
+	return fmt.Sprintf("## [%s] - %s", strings.TrimPrefix(c.Version.String(), "v"), date.Format("2006-01-02"))
+}
+
+// Markdown renders the changelog as a Keep-a-Changelog section
+func (c *Changelog) Markdown() string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", c.Heading())
+
+	for _, group := range c.groups() {
+		fmt.Fprintf(&b, "\n### %s\n\n", group.title)
+		for _, entry := range group.entries {
+			fmt.Fprintf(&b, "- %s (%s)\n", entry.summary("**%s:** %s"), shortHash(entry.Commit.Hash))
+		}
+	}
+
+	return b.String()
+}
+
+// Text renders the changes as plain text, e.g. for tag annotations, which
+// are read as plain text rather than Markdown, hence no headings
+func (c *Changelog) Text() string {
+
+	var b strings.Builder
+	for i, group := range c.groups() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:\n", group.title)
+		for _, entry := range group.entries {
+			fmt.Fprintf(&b, "- %s (%s)\n", entry.summary("%s: %s"), shortHash(entry.Commit.Hash))
+		}
+	}
+
+	return b.String()
+}
+
+// summary describes the change in a single line. Scoped changes are
+// formatted with scoped, e.g. "%s: %s" for "scope: description"
+func (e *ChangelogEntry) summary(scoped string) string {
+
+	cc := e.Conventional
+	if cc == nil || cc.Level() == LevelNone && !cc.Breaking {
+		return e.Commit.Message
+	}
+
+	if cc.Scope != "" {
+		return fmt.Sprintf(scoped, cc.Scope, cc.Description)
+	}
+
+	return cc.Description
+}
+
+// PrependChangelog adds a section to a Keep-a-Changelog file, above the
+// latest release. An existing unreleased section is replaced, by a new
+// unreleased section or by the release of its changes. The file is
+// created if it does not exist
+func PrependChangelog(path, section string) error {
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		content = []byte(changelogHeader)
+	} else if err != nil {
+		return fmt.Errorf("could not read changelog: %s", err.Error())
+	}
+
+	name := sectionName(strings.SplitN(section, "\n", 2)[0])
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+
+	// Released sections are never overwritten
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") && sectionName(line) == name && name != "Unreleased" {
+			return fmt.Errorf("changelog already contains version %s", name)
+		}
+	}
+
+	// Replace the unreleased section or insert above the latest release
+	start, end := len(lines), len(lines)
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if start < len(lines) {
+			end = i
+			break
+		}
+		if current := sectionName(line); current == name || current == "Unreleased" {
+			start = i
+		} else {
+			start, end = i, i
+			break
+		}
+	}
+
+	// Assemble the new content
+	var b strings.Builder
+	before := strings.TrimRight(strings.Join(lines[:start], "\n"), "\n")
+	if before != "" {
+		b.WriteString(before + "\n\n")
+	}
+	b.WriteString(strings.TrimRight(section, "\n") + "\n")
+	if after := strings.Join(lines[end:], "\n"); strings.TrimSpace(after) != "" {
+		b.WriteString("\n" + after + "\n")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("could not write changelog: %s", err.Error())
+	}
+
+	return nil
+}
+
+// sectionName extracts the version of a section heading, e.g. "1.2.0"
+// from "## [1.2.0] - 2017-09-07"
+func sectionName(heading string) string {
+	name := strings.TrimSpace(strings.TrimPrefix(heading, "##"))
+	if strings.HasPrefix(name, "[") {
+		if i := strings.Index(name, "]"); i > 0 {
+			return name[1:i]
+		}
+	}
+	return strings.SplitN(name, " ", 2)[0]
+}
+
+// WriteChangelog renders the changelog of a repository as Markdown. The
+// section is prepended to opts.Prepend if set, otherwise written to w
+func WriteChangelog(repo Repository, opts *ChangelogOptions, w io.Writer) error {
+
+	changelog, err := NewChangelog(repo, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Prepend == "" {
+		_, err := fmt.Fprint(w, changelog.Markdown())
+		return err
+	}
+
+	if err := PrependChangelog(opts.Prepend, changelog.Markdown()); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Added %s to %s\n", strings.TrimPrefix(changelog.Heading(), "## "), opts.Prepend)
+
+	return nil
+}
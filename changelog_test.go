@@ -0,0 +1,193 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// changelogFixture returns a repository with two releases, a pre-release
+// and unreleased changes
+func changelogFixture() *fakeRepository {
+
+	repo := newFakeRepository("/changelog")
+	repo.commit("feat: initial")
+	repo.tag("v1.0.0")
+	repo.commit("fix(cli): handle empty repositories")
+	repo.tag("v1.1.0-rc.1")
+	repo.commit("feat(cli): add --auto")
+	repo.commit("docs: update readme")
+	repo.commit("Update dependencies")
+	repo.tag("1.1.0")
+	repo.commit("refactor: split Increase\n\nBREAKING CHANGE: Increase takes options")
+	repo.commit("fix: typo")
+
+	return repo
+}
+
+func TestNewChangelog(t *testing.T) {
+
+	repo := changelogFixture()
+
+	tests := []struct {
+		opts     *ChangelogOptions
+		heading  string
+		previous string
+		counts   [4]int // breaking, features, fixes, other
+		err      string
+	}{
+		{&ChangelogOptions{}, "## [Unreleased]", "v1.1.0", [4]int{1, 0, 1, 0}, ""},
+		{&ChangelogOptions{To: "v1.1.0"}, "## [1.1.0] - 2017-09-07", "v1.0.0", [4]int{0, 1, 1, 2}, ""},
+		{&ChangelogOptions{From: "1.1.0-rc.1", To: "1.1.0"}, "## [1.1.0] - 2017-09-07", "v1.1.0-rc.1", [4]int{0, 1, 0, 2}, ""},
+		{&ChangelogOptions{To: "v1.1.0-rc.1"}, "## [1.1.0-rc.1] - 2017-09-07", "v1.0.0", [4]int{0, 0, 1, 0}, ""},
+		{&ChangelogOptions{To: "v1.0.0"}, "## [1.0.0] - 2017-09-07", "", [4]int{0, 1, 0, 0}, ""},
+		{&ChangelogOptions{To: "v2.0.0"}, "", "", [4]int{}, "unknown version 'v2.0.0'"},
+		{&ChangelogOptions{From: "v1.1.0", To: "v1.0.0"}, "", "", [4]int{}, "is not lower than"},
+	}
+
+	for i, test := range tests {
+		changelog, err := NewChangelog(repo, test.opts)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("TestNewChangelog: test %d failed: expected error '%s', got %v", i+1, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestNewChangelog: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+
+		previous := ""
+		if changelog.Previous != nil {
+			previous = changelog.Previous.String()
+		}
+		counts := [4]int{len(changelog.Breaking), len(changelog.Features), len(changelog.Fixes), len(changelog.Other)}
+		if changelog.Heading() != test.heading || previous != test.previous || counts != test.counts {
+			t.Errorf("TestNewChangelog: test %d failed: got %s since '%s' with %v", i+1, changelog.Heading(), previous, counts)
+		}
+	}
+
+	// The version on HEAD is the default upper bound
+	repo.tag("v2.0.0")
+	changelog, err := NewChangelog(repo, &ChangelogOptions{})
+	if err != nil || changelog.Heading() != "## [2.0.0] - 2017-09-07" || changelog.Previous.String() != "v1.1.0" {
+		t.Errorf("TestNewChangelog: unexpected changelog of a tagged HEAD: %v", err)
+	}
+
+}
+
+func TestNewChangelogComponents(t *testing.T) {
+
+	repo := newFakeRepository("/changelog/components")
+	repo.commit("feat: a", "api/api.go", "web/web.go")
+	repo.tag("api/v1.0.0", "web@1.0.0")
+	repo.commit("feat(web): b", "web/web.go")
+	repo.commit("fix(api): c", "api/api.go")
+	repo.tag("api/v1.0.1", "web@1.1.0")
+
+	// Components of the path scheme only list the commits of their
+	// directory, components of the at scheme list all commits
+	tests := []struct {
+		to       string
+		messages string
+	}{
+		{"api/v1.0.1", "fix(api): c"},
+		{"web@1.1.0", "fix(api): c feat(web): b"},
+	}
+
+	for i, test := range tests {
+		changelog, err := NewChangelog(repo, &ChangelogOptions{To: test.to})
+		if err != nil {
+			t.Errorf("TestNewChangelogComponents: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		messages := []string{}
+		for _, entries := range [][]*ChangelogEntry{changelog.Breaking, changelog.Features, changelog.Fixes, changelog.Other} {
+			for _, entry := range entries {
+				messages = append(messages, entry.Commit.Message)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(messages)))
+		if got := strings.Join(messages, " "); got != test.messages {
+			t.Errorf("TestNewChangelogComponents: test %d failed: got '%s', expected '%s'", i+1, got, test.messages)
+		}
+	}
+
+}
+
+func TestChangelogMarkdown(t *testing.T) {
+
+	repo := changelogFixture()
+
+	changelog, err := NewChangelog(repo, &ChangelogOptions{To: "v1.1.0"})
+	if err != nil {
+		t.Fatalf("TestChangelogMarkdown: unexpected error: %s", err.Error())
+	}
+
+	expected := "## [1.1.0] - 2017-09-07\n\n" +
+		"### Features\n\n" +
+		"- **cli:** add --auto (" + shortHash(changelog.Features[0].Commit.Hash) + ")\n\n" +
+		"### Fixes\n\n" +
+		"- **cli:** handle empty repositories (" + shortHash(changelog.Fixes[0].Commit.Hash) + ")\n\n" +
+		"### Other\n\n" +
+		"- Update dependencies (" + shortHash(changelog.Other[0].Commit.Hash) + ")\n" +
+		"- docs: update readme (" + shortHash(changelog.Other[1].Commit.Hash) + ")\n"
+
+	if markdown := changelog.Markdown(); markdown != expected {
+		t.Errorf("TestChangelogMarkdown: unexpected markdown:\n%s\nexpected:\n%s", markdown, expected)
+	}
+
+}
+
+func TestPrependChangelog(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	read := func() string {
+		content, _ := ioutil.ReadFile(path)
+		return string(content)
+	}
+
+	// New files start with the Keep-a-Changelog header
+	if err := PrependChangelog(path, "## [1.0.0] - 2017-09-07\n\n### Features\n\n- initial\n"); err != nil {
+		t.Fatalf("TestPrependChangelog: unexpected error: %s", err.Error())
+	}
+	if !strings.HasPrefix(read(), changelogHeader+"\n## [1.0.0] - 2017-09-07\n") {
+		t.Errorf("TestPrependChangelog: unexpected new file:\n%s", read())
+	}
+
+	// Unreleased changes go on top and are regenerated
+	PrependChangelog(path, "## [Unreleased]\n\n- first draft\n")
+	PrependChangelog(path, "## [Unreleased]\n\n- second draft\n")
+	if content := read(); strings.Contains(content, "first draft") || !strings.Contains(content, "## [Unreleased]\n\n- second draft\n\n## [1.0.0]") {
+		t.Errorf("TestPrependChangelog: unreleased section was not replaced:\n%s", content)
+	}
+
+	// Releases replace the unreleased section and go above the latest release
+	if err := PrependChangelog(path, "## [1.1.0] - 2017-09-08\n\n- second draft\n"); err != nil {
+		t.Fatalf("TestPrependChangelog: unexpected error: %s", err.Error())
+	}
+	expected := changelogHeader + "\n## [1.1.0] - 2017-09-08\n\n- second draft\n\n## [1.0.0] - 2017-09-07\n\n### Features\n\n- initial\n"
+	if content := read(); content != expected {
+		t.Errorf("TestPrependChangelog: unexpected content:\n%s\nexpected:\n%s", content, expected)
+	}
+	if err := PrependChangelog(path, "## [1.2.0] - 2017-09-09\n\n- more\n"); err != nil {
+		t.Fatalf("TestPrependChangelog: unexpected error: %s", err.Error())
+	}
+	if content := read(); !strings.Contains(content, changelogHeader+"\n## [1.2.0] - 2017-09-09\n\n- more\n\n## [1.1.0]") {
+		t.Errorf("TestPrependChangelog: release was not added above the latest release:\n%s", content)
+	}
+
+	// Released sections are never overwritten
+	if err := PrependChangelog(path, "## [1.0.0] - 2017-09-09\n"); err == nil {
+		t.Errorf("TestPrependChangelog: expected an error for an existing release")
+	}
+
+	// Unreadable changelogs
+	if err := PrependChangelog(filepath.Dir(path), "## [1.2.0]\n"); err == nil {
+		t.Errorf("TestPrependChangelog: expected an error for a directory")
+	}
+
+}
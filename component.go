@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag schemes of component versions, e.g. in monorepos. Unprefixed tags
+// (v1.2.3) belong to the repository itself
+const (
+	TagSchemePath = "path" // sub/dir/v1.2.3, like Go modules in subdirectories
+	TagSchemeAt   = "at"   // component@1.2.3
+)
+
+// ConfigTagScheme is the git configuration key holding the tag scheme of
+// new component versions
+const ConfigTagScheme = "version.tagScheme"
+
+// ParseTagScheme validates a tag scheme name
+func ParseTagScheme(name string) (string, error) {
+	switch scheme := strings.ToLower(name); scheme {
+	case "", TagSchemePath, TagSchemeAt:
+		return scheme, nil
+	}
+	return "", fmt.Errorf("unknown tag scheme '%s': choose path (sub/dir/v1.2.3) or at (component@1.2.3)", name)
+}
+
+// SplitTag separates the component prefix of a tag from its version, e.g.
+// "sub/dir" and "v1.2.3" for "sub/dir/v1.2.3", "api" and "1.2.3" for
+// "api@1.2.3". The component of unprefixed tags is empty
+func SplitTag(name string) (component, version string) {
+
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, "/"); i > 0 {
+		return name[:i], name[i+1:]
+	}
+
+	return "", name
+}
+
+// ComponentTag returns the tag name of a component's version
+func ComponentTag(component string, version *Version, scheme string) string {
+
+	switch {
+	case component == "":
+		return version.String()
+	case scheme == TagSchemeAt:
+		return component + "@" + strings.TrimPrefix(version.String(), "v")
+	}
+
+	return component + "/" + version.String()
+}
+
+// componentPaths returns the paths the commits of a component are limited
+// to. Components of the path scheme are directories, components of the at
+// scheme are names and, like the repository itself, consider all commits
+func componentPaths(component, scheme string) []string {
+	if component == "" || scheme != TagSchemePath {
+		return nil
+	}
+	return []string{component}
+}
+
+// tagScheme resolves the tag scheme of new component versions: the given
+// scheme, the repository's configuration or the path scheme
+func tagScheme(repo Repository, scheme string) (string, error) {
+
+	if scheme == "" {
+		value, err := repo.Config(ConfigTagScheme)
+		if err != nil {
+			return "", fmt.Errorf("could not read configuration: %s", err.Error())
+		}
+		scheme = value
+	}
+
+	scheme, err := ParseTagScheme(scheme)
+	if err != nil {
+		return "", err
+	}
+	if scheme == "" {
+		scheme = TagSchemePath
+	}
+
+	return scheme, nil
+}
+
+// validComponent checks whether a component name can be used in tags of
+// a scheme. Tags of the path scheme cannot contain "@", since it separates
+// the version of the at scheme
+func validComponent(component, scheme string) error {
+	if strings.HasPrefix(component, "/") || strings.HasSuffix(component, "/") || scheme == TagSchemePath && strings.Contains(component, "@") {
+		return fmt.Errorf("invalid component '%s'", component)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestSplitTag(t *testing.T) {
+
+	var tests = []struct {
+		tag       string
+		component string
+		version   string
+	}{
+		{"v1.2.3", "", "v1.2.3"},
+		{"1.2.3+build", "", "1.2.3+build"},
+		{"sub/dir/v1.2.3", "sub/dir", "v1.2.3"},
+		{"api@1.2.3-rc.1", "api", "1.2.3-rc.1"},
+		{"@scope/pkg@2.0.0", "@scope/pkg", "2.0.0"},
+		{"release/docs", "release", "docs"},
+		{"/v1.0.0", "", "/v1.0.0"},
+	}
+
+	for i, test := range tests {
+		if component, version := SplitTag(test.tag); component != test.component || version != test.version {
+			t.Errorf("TestSplitTag: test %d failed: expected '%s' and '%s', got '%s' and '%s'", i+1, test.component, test.version, component, version)
+		}
+	}
+
+}
+
+func TestComponentVersions(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/component/versions")
+	repo.commit("a")
+	repo.tag("v1.0.0", "tools/gen/v0.1.0", "api@2.0.0")
+	repo.commit("b")
+	repo.tag("v1.1.0", "tools/gen/v0.2.0", "api@2.0.1", "api@02.0.0")
+
+	versions, err := GetVersions(repo, TieBreakNone)
+	if err != nil {
+		t.Fatalf("TestComponentVersions: unexpected error: %s", err.Error())
+	}
+
+	// Series are ordered by component
+	expected := []string{"v1.1.0", "v1.0.0", "api@2.0.1", "api@2.0.0", "tools/gen/v0.2.0", "tools/gen/v0.1.0"}
+	tags := []string{}
+	for _, version := range versions.versions {
+		tags = append(tags, version.Tag)
+	}
+	if strings.Join(tags, " ") != strings.Join(expected, " ") {
+		t.Errorf("TestComponentVersions: expected %v, got %v", expected, tags)
+	}
+	if len(versions.malformed) != 1 || !strings.Contains(versions.malformed[0].Error(), "'api@02.0.0' at position 4") {
+		t.Errorf("TestComponentVersions: unexpected malformed tags %v", versions.malformed)
+	}
+	if series := versions.Component("api"); len(series.versions) != 2 || series.versions[0].Tag != "api@2.0.1" {
+		t.Errorf("TestComponentVersions: unexpected api series %v", series.versions)
+	}
+	if version := versions.Find("2.0.1"); version != nil {
+		t.Errorf("TestComponentVersions: normalized versions must not find components, got %s", version.Tag)
+	}
+
+	// The highest version of every component is listed
+	out := &bytes.Buffer{}
+	repos := []string{repo.path}
+	if err := printVersions(out, FormatJSON, repos, map[string]*Versions{repo.path: versions}, true, false); err != nil {
+		t.Fatalf("TestComponentVersions: unexpected error: %s", err.Error())
+	}
+	doc := &versionDocument{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil || len(doc.Versions) != 3 {
+		t.Fatalf("TestComponentVersions: unexpected JSON (%v):\n%s", err, out.String())
+	}
+	if doc.Versions[0].Component != "" || doc.Versions[1].Component != "api" || doc.Versions[2].Version != "v0.2.0" || doc.Versions[2].Component != "tools/gen" {
+		t.Errorf("TestComponentVersions: unexpected highest versions:\n%s", out.String())
+	}
+
+	out.Reset()
+	printVersionTable(out, repos, map[string]*Versions{repo.path: versions}, true, false)
+	for _, text := range []string{"Component", "tools/gen", "v0.2.0", "api", "v2.0.1"} {
+		if !strings.Contains(out.String(), text) {
+			t.Errorf("TestComponentVersions: table does not contain '%s':\n%s", text, out.String())
+		}
+	}
+	if len(versions.versions) != 6 {
+		t.Errorf("TestComponentVersions: printing the table modified the versions")
+	}
+
+}
+
+func TestIncreaseComponent(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/component/increase")
+	repo.commit("a")
+	repo.tag("v1.0.0", "tools/gen/v0.1.0", "api@2.0.0")
+	repo.commit("b")
+
+	var tests = []struct {
+		opts    *IncreaseOptions
+		config  string
+		tag     string
+		current string
+		err     string
+	}{
+		{&IncreaseOptions{Minor: true}, "", "v1.1.0", "v1.0.0", ""},
+		{&IncreaseOptions{Minor: true, Component: "tools/gen"}, "", "tools/gen/v0.2.0", "v0.1.0", ""},
+		{&IncreaseOptions{Major: true, Component: "api", TagScheme: TagSchemeAt}, "", "api@3.0.0", "v2.0.0", ""},
+		{&IncreaseOptions{Patch: true, Component: "api"}, TagSchemeAt, "api@2.0.1", "v2.0.0", ""},
+		{&IncreaseOptions{Patch: true, Component: "new"}, "", "new/v0.0.1", "", ""},
+		{&IncreaseOptions{Patch: true, Component: "api", TagScheme: "dash"}, "", "", "", "unknown tag scheme 'dash'"},
+		{&IncreaseOptions{Patch: true, Component: "a@b"}, "", "", "", "invalid component 'a@b'"},
+		{&IncreaseOptions{Patch: true, Component: "@scope/pkg", TagScheme: TagSchemeAt}, "", "@scope/pkg@0.0.1", "", ""},
+	}
+
+	for i, test := range tests {
+		repo.config[ConfigTagScheme] = test.config
+		plan, err := PlanIncrease(repo, test.opts)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("TestIncreaseComponent: test %d failed: expected error '%s', got %v", i+1, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestIncreaseComponent: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		if plan.Tag != test.tag || plan.Current != test.current || plan.Component != test.opts.Component {
+			t.Errorf("TestIncreaseComponent: test %d failed: unexpected plan %+v", i+1, plan)
+		}
+	}
+
+	// Other components may already be tagged on HEAD
+	repo.tag("api@2.1.0")
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Minor: true, Component: "tools/gen", Yes: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseComponent: unexpected error: %s", err.Error())
+	}
+	if repo.tags[len(repo.tags)-1].Name != "tools/gen/v0.2.0" || repo.messages["tools/gen/v0.2.0"] != "Version v0.2.0 of tools/gen" {
+		t.Errorf("TestIncreaseComponent: unexpected tag %+v", repo.tags[len(repo.tags)-1])
+	}
+	if err := Increase(repo, &IncreaseOptions{Minor: true, Component: "tools/gen", Yes: true}, strings.NewReader(""), out); err == nil {
+		t.Errorf("TestIncreaseComponent: expected an error for a tagged commit")
+	}
+
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Level is the version tick a change requires
+type Level int
+
+const (
+	LevelNone  Level = iota // no increase needed, e.g. docs or chores
+	LevelPatch              // bug fixes
+	LevelMinor              // new features
+	LevelMajor              // breaking changes
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelPatch:
+		return "patch"
+	case LevelMinor:
+		return "minor"
+	case LevelMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// ConventionalCommit is a commit following the Conventional Commits
+// specification (https://www.conventionalcommits.org/)
+type ConventionalCommit struct {
+	Commit      string `json:"commit"` // full hash
+	Type        string `json:"type"`   // lowercased, e.g. feat or fix
+	Scope       string `json:"scope"`
+	Breaking    bool   `json:"breaking"` // "!" in the header or a BREAKING CHANGE footer
+	Description string `json:"description"`
+}
+
+// conventionalHeader matches "type(scope)!: description"
+var conventionalHeader = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: +(\S.*)$`)
+
+// ParseConventionalCommit parses the message of a commit. Returns nil if
+// the commit does not follow the specification
+func ParseConventionalCommit(commit *Commit) *ConventionalCommit {
+
+	match := conventionalHeader.FindStringSubmatch(strings.TrimSpace(commit.Message))
+	if match == nil {
+		return nil
+	}
+
+	cc := &ConventionalCommit{
+		Commit:      commit.Hash,
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: match[4],
+	}
+
+	// Breaking change footers
+	for _, line := range strings.Split(commit.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			cc.Breaking = true
+		}
+	}
+
+	return cc
+}
+
+// String returns the commit header
+func (c *ConventionalCommit) String() string {
+	header := c.Type
+	if c.Scope != "" {
+		header += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		header += "!"
+	}
+	return header + ": " + c.Description
+}
+
+// Level returns the version tick the commit requires
+func (c *ConventionalCommit) Level() Level {
+	switch {
+	case c.Breaking:
+		return LevelMajor
+	case c.Type == "feat":
+		return LevelMinor
+	case c.Type == "fix":
+		return LevelPatch
+	}
+	return LevelNone
+}
+
+// AutoLevel derives the version tick required by a list of commits. The
+// commits requiring the returned level, i.e. the commits that drove the
+// decision, are returned as well
+func AutoLevel(commits []*Commit) (Level, []*ConventionalCommit) {
+
+	level := LevelNone
+	drivers := []*ConventionalCommit{}
+	for _, commit := range commits {
+		cc := ParseConventionalCommit(commit)
+		if cc == nil {
+			continue
+		}
+		switch l := cc.Level(); {
+		case l > level:
+			level = l
+			drivers = []*ConventionalCommit{cc}
+		case l == level && l != LevelNone:
+			drivers = append(drivers, cc)
+		}
+	}
+
+	return level, drivers
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+
+	tests := []struct {
+		message  string
+		body     string
+		ok       bool
+		typ      string
+		scope    string
+		breaking bool
+		level    Level
+	}{
+		{"feat: add --auto", "", true, "feat", "", false, LevelMinor},
+		{"fix(cli): handle empty repositories", "", true, "fix", "cli", false, LevelPatch},
+		{"Feat(api)!: drop v1 endpoints", "", true, "feat", "api", true, LevelMajor},
+		{"refactor: split Increase", "Some text\n\nBREAKING CHANGE: Increase takes options", true, "refactor", "", true, LevelMajor},
+		{"fix: typo", "BREAKING-CHANGE: none really", true, "fix", "", true, LevelMajor},
+		{"docs: update readme", "", true, "docs", "", false, LevelNone},
+		{"fix: mention BREAKING CHANGE: in the subject", "", true, "fix", "", false, LevelPatch},
+		{"Fix bug#32", "", false, "", "", false, LevelNone},
+		{"feat:missing space", "", false, "", "", false, LevelNone},
+		{"feat(: broken scope", "", false, "", "", false, LevelNone},
+	}
+
+	for i, test := range tests {
+		cc := ParseConventionalCommit(&Commit{Hash: "abc", Message: test.message, Body: test.body})
+		if (cc != nil) != test.ok {
+			t.Errorf("TestParseConventionalCommit: test %d failed: expected parsed=%v", i+1, test.ok)
+			continue
+		}
+		if cc == nil {
+			continue
+		}
+		if cc.Type != test.typ || cc.Scope != test.scope || cc.Breaking != test.breaking || cc.Level() != test.level || cc.Commit != "abc" {
+			t.Errorf("TestParseConventionalCommit: test %d failed: got %+v (level %s)", i+1, cc, cc.Level())
+		}
+	}
+
+}
+
+func TestAutoLevel(t *testing.T) {
+
+	commits := func(messages ...string) []*Commit {
+		list := []*Commit{}
+		for i, message := range messages {
+			list = append(list, &Commit{Hash: string(rune('a' + i)), Message: message})
+		}
+		return list
+	}
+
+	tests := []struct {
+		commits []*Commit
+		level   Level
+		drivers []string
+	}{
+		{commits(), LevelNone, nil},
+		{commits("docs: readme", "Merge branch 'x'"), LevelNone, nil},
+		{commits("fix: a", "docs: b", "fix: c"), LevelPatch, []string{"a", "c"}},
+		{commits("fix: a", "feat: b", "feat(x): c"), LevelMinor, []string{"b", "c"}},
+		{commits("feat: a", "fix!: b", "feat: c"), LevelMajor, []string{"b"}},
+	}
+
+	for i, test := range tests {
+		level, drivers := AutoLevel(test.commits)
+		if level != test.level || len(drivers) != len(test.drivers) {
+			t.Errorf("TestAutoLevel: test %d failed: got %s with %d drivers", i+1, level, len(drivers))
+			continue
+		}
+		for j, driver := range drivers {
+			if driver.Commit != test.drivers[j] {
+				t.Errorf("TestAutoLevel: test %d failed: unexpected driver %s", i+1, driver.Commit)
+			}
+		}
+	}
+
+}
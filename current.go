@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/vaitekunas/version/semver"
+)
+
+// Description is the version of HEAD relative to the highest version
+// reachable from it, like git describe
+type Description struct {
+	Schema     int    `json:"schema"`
+	Repository string `json:"repository"`
+	Component  string `json:"component,omitempty"`
+	Version    string `json:"version"`  // version of HEAD or the highest reachable version, empty if there is none
+	Tag        string `json:"tag"`      // tag of the version
+	Commit     string `json:"commit"`   // full hash of HEAD
+	Distance   int    `json:"distance"` // number of commits since the version
+	Exact      bool   `json:"exact"`    // HEAD is tagged with the version
+	Dirty      bool   `json:"dirty"`    // tracked files were modified
+	Describe   string `json:"describe"` // e.g. v1.2.3-5-gabc1234-dirty
+	Pseudo     string `json:"pseudo"`   // semver-valid pseudo-version, e.g. v1.2.4-0.20261018120000-abc123def456
+}
+
+// CurrentOptions holds the parameters of the current version
+type CurrentOptions struct {
+	Component string // only versions of this component are considered, see SplitTag
+	Pseudo    bool   // print the pseudo-version instead of the description
+	Format    string // output format: json or empty (a single line)
+}
+
+// Describe determines the version of HEAD: its version if it is tagged,
+// otherwise the highest version reachable from HEAD and the number of
+// commits since then. Dirty states only consider tracked files
+func Describe(repo Repository, component string) (*Description, error) {
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get last commit: %s", err.Error())
+	}
+
+	versions, err := GetVersions(repo, TieBreakNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine version: %s", err.Error())
+	}
+
+	history, err := repo.Commits("", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits: %s", err.Error())
+	}
+	reachable := map[string]bool{}
+	for _, commit := range history {
+		reachable[commit.Hash] = true
+	}
+
+	dirty, err := repo.Dirty()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Description{
+		Schema:     SchemaVersion,
+		Repository: repo.Path(),
+		Component:  component,
+		Commit:     head.Hash,
+		Distance:   len(history),
+		Dirty:      dirty,
+	}
+
+	// Versions are ordered from the highest to the lowest, a version of
+	// HEAD itself wins over higher versions of its ancestors
+	var current *Version
+	for _, version := range versions.Component(component).versions {
+		if version.Commit == head.Hash {
+			current = version
+			break
+		}
+		if current == nil && reachable[version.Commit] {
+			current = version
+		}
+	}
+
+	if current != nil {
+		since, err := repo.Commits(current.Commit, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("could not list commits: %s", err.Error())
+		}
+		d.Version, d.Tag, d.Distance, d.Exact = current.String(), current.Tag, len(since), len(since) == 0
+	}
+
+	scheme, err := tagScheme(repo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	d.Describe = describe(d, current, scheme)
+	d.Pseudo = pseudoVersion(d, current, head)
+
+	return d, nil
+}
+
+// describe formats a description like git describe: the tag, followed by
+// the distance and the abbreviated hash if HEAD is not tagged, and
+// "-dirty" if tracked files were modified. v0.0.0 stands in for the tag
+// if no version is reachable
+func describe(d *Description, current *Version, scheme string) string {
+
+	str := ComponentTag(d.Component, &Version{}, scheme)
+	if current != nil {
+		str = current.Tag
+	}
+	if !d.Exact {
+		str = fmt.Sprintf("%s-%d-g%s", str, d.Distance, shortHash(d.Commit))
+	}
+	if d.Dirty {
+		str += "-dirty"
+	}
+
+	return str
+}
+
+// pseudoVersion formats a description as a Go pseudo-version, see
+// https://go.dev/ref/mod#pseudo-versions. It orders after the current
+// version and before the next release: vX.Y.(Z+1)-0.yyyymmddhhmmss-hash
+// after a release, vX.Y.Z-pre.0.yyyymmddhhmmss-hash after a pre-release.
+// The timestamp is the committer date, like the go command uses. Tagged
+// commits keep their version, modifications add "+dirty"
+func pseudoVersion(d *Description, current *Version, head *Commit) string {
+
+	base := semver.Version{}
+	if current != nil {
+		base = semver.Version{Major: current.Major, Minor: current.Minor, Patch: current.Patch, Special: current.Special}
+	}
+	if d.Exact {
+		base = current.Version
+	}
+
+	hash := d.Commit
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	stamp := fmt.Sprintf("%s-%s", head.CommitDate.UTC().Format("20060102150405"), hash)
+
+	switch {
+	case d.Exact:
+	case current == nil:
+		base.Special = stamp
+	case base.Special == "":
+		base.Patch++
+		base.Special = "0." + stamp
+	default:
+		base.Special += ".0." + stamp
+	}
+	if d.Dirty && base.Build != "" {
+		base.Build += ".dirty"
+	} else if d.Dirty {
+		base.Build = "dirty"
+	}
+
+	return base.String()
+}
+
+// Current prints the version of HEAD, see Describe
+func Current(repo Repository, opts *CurrentOptions, w io.Writer) error {
+
+	if opts.Format != "" && opts.Format != FormatJSON {
+		return fmt.Errorf("unknown format '%s': choose json", opts.Format)
+	}
+
+	d, err := Describe(repo, opts.Component)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case opts.Format == FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(d)
+	case opts.Pseudo:
+		_, err = fmt.Fprintln(w, d.Pseudo)
+	default:
+		_, err = fmt.Fprintln(w, d.Describe)
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+
+	tests := []struct {
+		setup     func(repo *fakeRepository)
+		component string
+		describe  string // {hash} is replaced by the abbreviated hash of HEAD
+		pseudo    string // {hash} is replaced by the hash of HEAD, shortened to 12 characters
+	}{
+		// No version
+		{func(repo *fakeRepository) { repo.commit("a"); repo.commit("b") }, "", "v0.0.0-2-g{hash}", "v0.0.0-20170907143000-{hash}"},
+
+		// Commits since a release, a pre-release
+		{func(repo *fakeRepository) {
+			repo.commit("a")
+			repo.tag("v1.2.3")
+			repo.commit("b")
+			repo.commit("c")
+		}, "", "v1.2.3-2-g{hash}", "v1.2.4-0.20170907153000-{hash}"},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v2.0.0-rc.1"); repo.commit("b") }, "", "v2.0.0-rc.1-1-g{hash}", "v2.0.0-rc.1.0.20170907143000-{hash}"},
+
+		// Tagged and dirty
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.2.3+exp") }, "", "v1.2.3+exp", "v1.2.3+exp"},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.2.3"); repo.dirty = true }, "", "v1.2.3-dirty", "v1.2.3+dirty"},
+		{func(repo *fakeRepository) {
+			repo.commit("a")
+			repo.tag("v1.2.3")
+			repo.commit("b")
+			repo.dirty = true
+		}, "", "v1.2.3-1-g{hash}-dirty", "v1.2.4-0.20170907143000-{hash}+dirty"},
+
+		// Versions of other branches are not reachable
+		{func(repo *fakeRepository) {
+			repo.commit("a")
+			repo.tag("v1.2.3")
+			repo.checkout("feature")
+			repo.commit("b")
+			repo.tag("v3.0.0")
+			repo.checkout("master")
+			repo.commit("c")
+		}, "", "v1.2.3-1-g{hash}", "v1.2.4-0.20170907153000-{hash}"},
+
+		// A version of HEAD wins over higher versions of its ancestors
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v2.0.0"); repo.commit("b"); repo.tag("v1.0.1") }, "", "v1.0.1", "v1.0.1"},
+
+		// Components
+		{func(repo *fakeRepository) {
+			repo.commit("a")
+			repo.tag("v1.2.3", "api/v0.1.0")
+			repo.commit("b")
+		}, "api", "api/v0.1.0-1-g{hash}", "v0.1.1-0.20170907143000-{hash}"},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.2.3") }, "api", "api/v0.0.0-1-g{hash}", "v0.0.0-20170907133000-{hash}"},
+	}
+
+	for i, test := range tests {
+		repo := newFakeRepository("/describe")
+		test.setup(repo)
+
+		d, err := Describe(repo, test.component)
+		if err != nil {
+			t.Errorf("TestDescribe: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+
+		head, _ := repo.Head()
+		describe := strings.Replace(test.describe, "{hash}", shortHash(head.Hash), 1)
+		pseudo := strings.Replace(test.pseudo, "{hash}", head.Hash[:12], 1)
+		if d.Describe != describe || d.Pseudo != pseudo {
+			t.Errorf("TestDescribe: test %d failed: got '%s' and '%s', expected '%s' and '%s'", i+1, d.Describe, d.Pseudo, describe, pseudo)
+		}
+	}
+
+}
+
+func TestCurrent(t *testing.T) {
+
+	repo := newFakeRepository("/current")
+	repo.commit("a")
+	repo.tag("v1.2.3")
+	repo.commit("b")
+	repo.commit("c")
+	head, _ := repo.Head()
+
+	out := &bytes.Buffer{}
+	if err := Current(repo, &CurrentOptions{Pseudo: true}, out); err != nil {
+		t.Fatalf("TestCurrent: unexpected error: %s", err.Error())
+	}
+	if expected := "v1.2.4-0.20170907153000-" + head.Hash[:12] + "\n"; out.String() != expected {
+		t.Errorf("TestCurrent: expected '%s', got '%s'", expected, out.String())
+	}
+
+	out.Reset()
+	if err := Current(repo, &CurrentOptions{Format: FormatJSON}, out); err != nil {
+		t.Fatalf("TestCurrent: unexpected error: %s", err.Error())
+	}
+	d := &Description{}
+	if err := json.Unmarshal(out.Bytes(), d); err != nil {
+		t.Fatalf("TestCurrent: invalid JSON: %s", err.Error())
+	}
+	if d.Version != "v1.2.3" || d.Tag != "v1.2.3" || d.Distance != 2 || d.Exact || d.Dirty || d.Commit != head.Hash {
+		t.Errorf("TestCurrent: unexpected description:\n%s", out.String())
+	}
+
+	if err := Current(repo, &CurrentOptions{Format: FormatYAML}, out); err == nil {
+		t.Errorf("TestCurrent: expected an error for an unsupported format")
+	}
+
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	FormatTable    = "table"    // colored box table (default)
+	FormatJSON     = "json"     // JSON document, see VersionRecord
+	FormatYAML     = "yaml"     // YAML document with the same fields as JSON
+	FormatCSV      = "csv"      // comma separated values with a header
+	FormatTSV      = "tsv"      // tab separated values with a header
+	FormatMarkdown = "markdown" // markdown table
+	FormatPlain    = "plain"    // aligned columns without colors
+)
+
+// SchemaVersion is the version of the JSON/YAML output schema. It is only
+// increased when fields are removed or change their meaning
+const SchemaVersion = 1
+
+// ParseFormat validates an output format name
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(name); format {
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV, FormatMarkdown, FormatPlain:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format '%s': choose table, json, yaml, csv, tsv, markdown or plain", name)
+}
+
+// VersionRecord is the machine-readable representation of a version
+type VersionRecord struct {
+	Repository string     `json:"repository"`          // path of the repository
+	Component  string     `json:"component,omitempty"` // tag prefix of monorepo components
+	Tag        string     `json:"tag"`                 // raw tag name
+	Version    string     `json:"version"`             // normalized version, e.g. v1.2.3-rc.1+build
+	Commit     string     `json:"commit"`              // full hash of the tagged commit
+	Date       *time.Time `json:"date,omitempty"`      // commit date (RFC 3339), unknown for tags that were not fetched
+	Major      int        `json:"major"`
+	Minor      int        `json:"minor"`
+	Patch      int        `json:"patch"`
+	Special    string     `json:"special"`             // pre-release version, empty for releases
+	Build      string     `json:"build"`               // build metadata
+	Signature  string     `json:"signature,omitempty"` // signature state, only set when verified
+	Kind       string     `json:"kind,omitempty"`      // annotated or lightweight, only set with tag metadata
+	Tagger     string     `json:"tagger,omitempty"`    // "Name <email>" of annotated tags
+	TagDate    *time.Time `json:"tag_date,omitempty"`  // tagger date (RFC 3339) of annotated tags
+	Message    string     `json:"message,omitempty"`   // annotation message of annotated tags
+}
+
+// Kinds of tags
+const (
+	TagAnnotated   = "annotated"
+	TagLightweight = "lightweight"
+)
+
+// versionDocument is the top-level JSON/YAML document
+type versionDocument struct {
+	Schema   int              `json:"schema"`
+	Versions []*VersionRecord `json:"versions"`
+}
+
+// NewVersionRecord creates a record of a repository's version
+func NewVersionRecord(repo string, v *Version) *VersionRecord {
+
+	record := &VersionRecord{
+		Repository: repo,
+		Component:  v.Component,
+		Tag:        v.Tag,
+		Version:    v.String(),
+		Commit:     v.Commit,
+		Major:      v.Major,
+		Minor:      v.Minor,
+		Patch:      v.Patch,
+		Special:    v.Special,
+		Build:      v.Build,
+		Signature:  v.Signature,
+	}
+	if !v.Date.IsZero() {
+		date := v.Date
+		record.Date = &date
+	}
+
+	return record
+}
+
+// addTagInfo adds the metadata of the version's tag to the record.
+// Lightweight tags have neither a tagger nor a tag date of their own
+func (r *VersionRecord) addTagInfo(v *Version) {
+
+	r.Kind = TagLightweight
+	if !v.Annotated {
+		return
+	}
+
+	date := v.TagDate
+	r.Kind, r.Tagger, r.TagDate, r.Message = TagAnnotated, v.Tagger, &date, v.Message
+}
+
+// versionRecords flattens the versions of all repositories. Only the
+// highest version of every repository and component is kept if last is
+// set, tag metadata is added if tagInfo is set
+func versionRecords(repos []string, repoVersions map[string]*Versions, last, tagInfo bool) []*VersionRecord {
+
+	records := []*VersionRecord{}
+	for _, repo := range repos {
+		versions, ok := repoVersions[repo]
+		if !ok {
+			continue
+		}
+		listed := versions.versions
+		if last {
+			listed = versions.highest()
+		}
+		for _, version := range listed {
+			record := NewVersionRecord(repo, version)
+			if tagInfo {
+				record.addTagInfo(version)
+			}
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// printVersions displays version data in the requested format. Tag
+// metadata (kind, tagger, tag date and message) is shown if tagInfo is set
+func printVersions(w io.Writer, format string, repos []string, repoVersions map[string]*Versions, last, tagInfo bool) error {
+
+	if format == FormatTable || format == "" {
+		printVersionTable(w, repos, repoVersions, last, tagInfo)
+		return nil
+	}
+
+	records := versionRecords(repos, repoVersions, last, tagInfo)
+
+	switch format {
+
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(&versionDocument{Schema: SchemaVersion, Versions: records})
+
+	case FormatYAML:
+		return writeYAML(w, records)
+
+	case FormatCSV, FormatTSV:
+		cw := csv.NewWriter(w)
+		if format == FormatTSV {
+			cw.Comma = '\t'
+		}
+		header := recordHeader(records)
+		cw.Write(header)
+		for _, record := range records {
+			cw.Write(record.fields(header))
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FormatMarkdown:
+		header := recordHeader(records)
+		fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+		// Pipes and line breaks of messages would break the table rows
+		escape := strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+		for _, record := range records {
+			fields := record.fields(header)
+			for i, field := range fields {
+				fields[i] = escape.Replace(field)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(fields, " | "))
+		}
+		return nil
+
+	case FormatPlain:
+		components := false
+		for _, record := range records {
+			components = components || record.Component != ""
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, record := range records {
+			date := formatDate(record.Date, "2006-01-02 15:04")
+			if date == "" {
+				date = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t", record.Repository, date, shortHash(record.Commit))
+			if components && record.Component == "" {
+				fmt.Fprintf(tw, "-\t")
+			} else if components {
+				fmt.Fprintf(tw, "%s\t", record.Component)
+			}
+			fmt.Fprintf(tw, "%s", record.Version)
+			if record.Signature != "" {
+				fmt.Fprintf(tw, "\t%s", record.Signature)
+			}
+			if record.Kind != "" {
+				tagDate, tagger, message := formatDate(record.TagDate, "2006-01-02 15:04"), record.Tagger, firstLine(record.Message)
+				if record.Kind == TagLightweight {
+					tagDate, tagger = "-", "-"
+				}
+				if message == "" {
+					message = "-"
+				}
+				fmt.Fprintf(tw, "\t%s\t%s\t%s\t%s", record.Kind, tagDate, tagger, message)
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+// recordHeader returns the column names of tabular formats. Optional
+// columns are included if any of the records has a value
+func recordHeader(records []*VersionRecord) []string {
+
+	header := []string{"repository", "tag", "version", "commit", "date", "major", "minor", "patch", "special", "build"}
+	for _, optional := range []string{"component", "signature", "kind", "tagger", "tag_date", "message"} {
+		for _, record := range records {
+			if record.fields([]string{optional})[0] != "" {
+				header = append(header, optional)
+				break
+			}
+		}
+	}
+
+	return header
+}
+
+// fields returns the record as a row of a tabular format
+func (r *VersionRecord) fields(header []string) []string {
+
+	values := map[string]string{
+		"repository": r.Repository,
+		"tag":        r.Tag,
+		"version":    r.Version,
+		"commit":     r.Commit,
+		"date":       formatDate(r.Date, time.RFC3339),
+		"major":      strconv.Itoa(r.Major),
+		"minor":      strconv.Itoa(r.Minor),
+		"patch":      strconv.Itoa(r.Patch),
+		"special":    r.Special,
+		"build":      r.Build,
+		"signature":  r.Signature,
+		"component":  r.Component,
+		"kind":       r.Kind,
+		"tagger":     r.Tagger,
+		"tag_date":   formatDate(r.TagDate, time.RFC3339),
+		"message":    r.Message,
+	}
+
+	fields := make([]string, len(header))
+	for i, column := range header {
+		fields[i] = values[column]
+	}
+
+	return fields
+}
+
+// formatDate formats a date, unknown dates are empty
+func formatDate(date *time.Time, layout string) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(layout)
+}
+
+// firstLine returns the first line of a tag message
+func firstLine(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
+
+// writeYAML writes the records as a YAML document. Strings are written as
+// double-quoted scalars, which use the same escaping as JSON strings
+func writeYAML(w io.Writer, records []*VersionRecord) error {
+
+	quote := func(s string) string {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.Encode(s)
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+
+	fmt.Fprintf(w, "schema: %d\n", SchemaVersion)
+	if len(records) == 0 {
+		_, err := fmt.Fprintf(w, "versions: []\n")
+		return err
+	}
+
+	fmt.Fprintf(w, "versions:\n")
+	for _, r := range records {
+		fmt.Fprintf(w, "  - repository: %s\n", quote(r.Repository))
+		fmt.Fprintf(w, "    tag: %s\n", quote(r.Tag))
+		fmt.Fprintf(w, "    version: %s\n", quote(r.Version))
+		fmt.Fprintf(w, "    commit: %s\n", quote(r.Commit))
+		if r.Date != nil {
+			fmt.Fprintf(w, "    date: %s\n", quote(r.Date.Format(time.RFC3339)))
+		}
+		fmt.Fprintf(w, "    major: %d\n", r.Major)
+		fmt.Fprintf(w, "    minor: %d\n", r.Minor)
+		fmt.Fprintf(w, "    patch: %d\n", r.Patch)
+		fmt.Fprintf(w, "    special: %s\n", quote(r.Special))
+		if _, err := fmt.Fprintf(w, "    build: %s\n", quote(r.Build)); err != nil {
+			return err
+		}
+		if r.Signature != "" {
+			fmt.Fprintf(w, "    signature: %s\n", quote(r.Signature))
+		}
+		if r.Component != "" {
+			fmt.Fprintf(w, "    component: %s\n", quote(r.Component))
+		}
+		if r.Kind != "" {
+			fmt.Fprintf(w, "    kind: %s\n", quote(r.Kind))
+		}
+		if r.Tagger != "" {
+			fmt.Fprintf(w, "    tagger: %s\n", quote(r.Tagger))
+		}
+		if r.TagDate != nil {
+			fmt.Fprintf(w, "    tag_date: %s\n", quote(r.TagDate.Format(time.RFC3339)))
+		}
+		if r.Message != "" {
+			fmt.Fprintf(w, "    message: %s\n", quote(r.Message))
+		}
+	}
+
+	return nil
+}
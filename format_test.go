@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vaitekunas/version/semver"
+)
+
+// formatFixture returns two repositories with a few versions each
+func formatFixture() ([]string, map[string]*Versions) {
+
+	version := func(tag, commit string) *Version {
+		return &Version{
+			Version: *semver.MustParse(tag),
+			Tag:     tag,
+			Commit:  commit,
+			Date:    time.Date(2017, 9, 7, 16, 9, 0, 0, time.UTC),
+		}
+	}
+
+	repos := []string{"/src/alpha", "/src/beta|gamma"}
+	repoVersions := map[string]*Versions{
+		"/src/alpha": {versions: []*Version{
+			version("v0.14.1", "46a2962f4433c7a1f6e7d0ffbc72c9c63ba06c34"),
+			version("v0.14.0-rc.1+exp", "1788554f4433c7a1f6e7d0ffbc72c9c63ba06c34"),
+		}},
+		"/src/beta|gamma": {versions: []*Version{
+			version("2.0.0", "7557dd4f4433c7a1f6e7d0ffbc72c9c63ba06c34"),
+		}},
+	}
+
+	return repos, repoVersions
+}
+
+func TestParseFormat(t *testing.T) {
+
+	for _, name := range []string{"table", "JSON", "yaml", "csv", "tsv", "markdown", "plain"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("TestParseFormat: unexpected error for %s: %s", name, err.Error())
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("TestParseFormat: expected an error for an unknown format")
+	}
+
+}
+
+func TestPrintVersionsJSON(t *testing.T) {
+
+	repos, repoVersions := formatFixture()
+
+	out := &bytes.Buffer{}
+	if err := printVersions(out, FormatJSON, repos, repoVersions, false, false); err != nil {
+		t.Fatalf("TestPrintVersionsJSON: unexpected error: %s", err.Error())
+	}
+
+	doc := struct {
+		Schema   int                      `json:"schema"`
+		Versions []map[string]interface{} `json:"versions"`
+	}{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("TestPrintVersionsJSON: invalid JSON: %s", err.Error())
+	}
+
+	if doc.Schema != SchemaVersion || len(doc.Versions) != 3 {
+		t.Fatalf("TestPrintVersionsJSON: unexpected document: %s", out.String())
+	}
+
+	expected := map[string]interface{}{
+		"repository": "/src/alpha",
+		"tag":        "v0.14.0-rc.1+exp",
+		"version":    "v0.14.0-rc.1+exp",
+		"commit":     "1788554f4433c7a1f6e7d0ffbc72c9c63ba06c34",
+		"date":       "2017-09-07T16:09:00Z",
+		"major":      float64(0),
+		"minor":      float64(14),
+		"patch":      float64(0),
+		"special":    "rc.1",
+		"build":      "exp",
+	}
+	for key, value := range expected {
+		if doc.Versions[1][key] != value {
+			t.Errorf("TestPrintVersionsJSON: field %s: got %v, expected %v", key, doc.Versions[1][key], value)
+		}
+	}
+	if len(doc.Versions[1]) != len(expected) {
+		t.Errorf("TestPrintVersionsJSON: unexpected fields: %v", doc.Versions[1])
+	}
+
+	// Only the highest versions
+	out.Reset()
+	printVersions(out, FormatJSON, repos, repoVersions, true, false)
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil || len(doc.Versions) != 2 {
+		t.Errorf("TestPrintVersionsJSON: expected 2 highest versions: %s", out.String())
+	}
+
+}
+
+func TestPrintVersionsTabular(t *testing.T) {
+
+	repos, repoVersions := formatFixture()
+
+	for _, format := range []string{FormatCSV, FormatTSV} {
+		out := &bytes.Buffer{}
+		if err := printVersions(out, format, repos, repoVersions, false, false); err != nil {
+			t.Fatalf("TestPrintVersionsTabular: %s: unexpected error: %s", format, err.Error())
+		}
+
+		r := csv.NewReader(out)
+		if format == FormatTSV {
+			r.Comma = '\t'
+		}
+		rows, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("TestPrintVersionsTabular: %s: invalid output: %s", format, err.Error())
+		}
+		if len(rows) != 4 || strings.Join(rows[0], ",") != strings.Join(recordHeader(nil), ",") {
+			t.Fatalf("TestPrintVersionsTabular: %s: unexpected rows: %v", format, rows)
+		}
+		if rows[3][0] != "/src/beta|gamma" || rows[3][1] != "2.0.0" || rows[3][2] != "v2.0.0" || rows[3][5] != "2" {
+			t.Errorf("TestPrintVersionsTabular: %s: unexpected row: %v", format, rows[3])
+		}
+	}
+
+}
+
+func TestPrintVersionsText(t *testing.T) {
+
+	repos, repoVersions := formatFixture()
+
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{FormatMarkdown, []string{
+			"| repository | tag | version | commit | date | major | minor | patch | special | build |",
+			"| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |",
+			"| /src/beta\\|gamma | 2.0.0 | v2.0.0 | 7557dd4f4433c7a1f6e7d0ffbc72c9c63ba06c34 | 2017-09-07T16:09:00Z | 2 | 0 | 0 |  |  |",
+		}},
+		{FormatPlain, []string{
+			"/src/alpha       2017-09-07 16:09  46a2962  v0.14.1",
+			"/src/beta|gamma  2017-09-07 16:09  7557dd4  v2.0.0",
+		}},
+		{FormatYAML, []string{
+			"schema: 1\nversions:\n  - repository: \"/src/alpha\"\n    tag: \"v0.14.1\"\n",
+			"    special: \"rc.1\"\n    build: \"exp\"\n",
+			"    major: 2\n",
+		}},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		if err := printVersions(out, test.format, repos, repoVersions, false, false); err != nil {
+			t.Fatalf("TestPrintVersionsText: %s: unexpected error: %s", test.format, err.Error())
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("TestPrintVersionsText: %s: output does not contain '%s':\n%s", test.format, expected, out.String())
+			}
+		}
+	}
+
+}
+
+func TestPrintVersionsTagInfo(t *testing.T) {
+
+	repos, repoVersions := formatFixture()
+	annotated := repoVersions["/src/alpha"].versions[0]
+	annotated.Annotated, annotated.Tagger, annotated.Message = true, "Tester <tester@example.com>", "Release\n\nNotes | more"
+	annotated.TagDate = time.Date(2018, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	// Tag metadata is only shown on request
+	for _, tagInfo := range []bool{false, true} {
+		out := &bytes.Buffer{}
+		if err := printVersions(out, FormatJSON, repos, repoVersions, false, tagInfo); err != nil {
+			t.Fatalf("TestPrintVersionsTagInfo: unexpected error: %s", err.Error())
+		}
+		document := &versionDocument{}
+		if err := json.Unmarshal(out.Bytes(), document); err != nil {
+			t.Fatalf("TestPrintVersionsTagInfo: invalid JSON: %s", err.Error())
+		}
+
+		expected := []string{"   ", "   ", "   "}
+		if tagInfo {
+			expected = []string{"annotated Tester <tester@example.com> 2018-01-02T03:04:00Z Release\n\nNotes | more", "lightweight   ", "lightweight   "}
+		}
+		for i, record := range document.Versions {
+			got := strings.Join([]string{record.Kind, record.Tagger, formatDate(record.TagDate, time.RFC3339), record.Message}, " ")
+			if got != expected[i] {
+				t.Errorf("TestPrintVersionsTagInfo: test %d failed: got '%s', expected '%s'", i+1, got, expected[i])
+			}
+		}
+	}
+
+	// Taggers are written as they are, without escaping HTML
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		out := &bytes.Buffer{}
+		if err := printVersions(out, format, repos, repoVersions, false, true); err != nil {
+			t.Fatalf("TestPrintVersionsTagInfo: unexpected error: %s", err.Error())
+		}
+		if !strings.Contains(out.String(), `"Tester <tester@example.com>"`) {
+			t.Errorf("TestPrintVersionsTagInfo: %s: tagger was escaped:\n%s", format, out.String())
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if err := printVersions(out, FormatCSV, repos, repoVersions, false, true); err != nil {
+		t.Fatalf("TestPrintVersionsTagInfo: unexpected error: %s", err.Error())
+	}
+	if header := strings.SplitN(out.String(), "\n", 2)[0]; !strings.HasSuffix(header, ",kind,tagger,tag_date,message") {
+		t.Errorf("TestPrintVersionsTagInfo: unexpected header '%s'", header)
+	}
+
+	// Messages span a single markdown row, plain output shows their first line
+	out.Reset()
+	if err := printVersions(out, FormatMarkdown, repos, repoVersions, false, true); err != nil {
+		t.Fatalf("TestPrintVersionsTagInfo: unexpected error: %s", err.Error())
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 5 || !strings.HasSuffix(lines[2], " | Release<br><br>Notes \\| more |") {
+		t.Errorf("TestPrintVersionsTagInfo: unexpected markdown:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printVersions(out, FormatPlain, repos, repoVersions, false, true); err != nil {
+		t.Fatalf("TestPrintVersionsTagInfo: unexpected error: %s", err.Error())
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 || !strings.HasSuffix(lines[0], "  Release") || !strings.HasSuffix(lines[1], "  -") {
+		t.Errorf("TestPrintVersionsTagInfo: unexpected plain output:\n%s", out.String())
+	}
+
+}
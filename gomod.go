@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModuleRewrite describes the update of a Go module path to a new major
+// version, see https://go.dev/ref/mod#major-version-suffixes
+type ModuleRewrite struct {
+	Path    string   `json:"path"`     // current module path
+	NewPath string   `json:"new_path"` // module path with the new major version suffix
+	Files   []string `json:"files"`    // files to be rewritten, relative to the module root
+
+	dir      string
+	original map[string][]byte // contents of the rewritten files, by path
+}
+
+// Major version suffixes of module paths, i.e. /vN for N >= 2 and .vN for
+// gopkg.in paths
+var (
+	majorSuffix = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+	gopkgSuffix = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+// ReadModulePath returns the module path of the go.mod file in dir, or an
+// empty string if there is none
+func ReadModulePath(dir string) (string, error) {
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not read go.mod: %s", err.Error())
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(strings.SplitN(line, "//", 2)[0])
+		if len(fields) == 2 && fields[0] == "module" {
+			if path, err := strconv.Unquote(fields[1]); err == nil {
+				return path, nil
+			}
+			return fields[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("go.mod does not declare a module path")
+}
+
+// ModulePathMajor returns the module path suited for a major version: v0
+// and v1 have no suffix, v2 and higher end in /vN (.vN for gopkg.in)
+func ModulePathMajor(path string, major int) string {
+
+	if strings.HasPrefix(path, "gopkg.in/") {
+		return fmt.Sprintf("%s.v%d", gopkgSuffix.ReplaceAllString(path, ""), major)
+	}
+
+	path = majorSuffix.ReplaceAllString(path, "")
+	if major >= 2 {
+		return fmt.Sprintf("%s/v%d", path, major)
+	}
+
+	return path
+}
+
+// planModuleRewrite checks whether the module in dir fits a new major
+// version. Returns nil if there is no go.mod or the module path matches
+func planModuleRewrite(dir string, major int) (*ModuleRewrite, error) {
+
+	path, err := ReadModulePath(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	newPath := ModulePathMajor(path, major)
+	if newPath == path {
+		return nil, nil
+	}
+
+	rewrite := &ModuleRewrite{Path: path, NewPath: newPath, dir: dir}
+	if rewrite.Files, err = rewrite.apply(false); err != nil {
+		return nil, err
+	}
+
+	return rewrite, nil
+}
+
+// Apply rewrites the module path in go.mod and the import paths of all Go
+// files of the module. Files rewritten before a failure are restored
+func (m *ModuleRewrite) Apply() error {
+	if _, err := m.apply(true); err != nil {
+		m.Restore()
+		return err
+	}
+	return nil
+}
+
+// Paths returns the files to be rewritten, joined with the module root
+func (m *ModuleRewrite) Paths() []string {
+	paths := []string{}
+	for _, file := range m.Files {
+		paths = append(paths, filepath.Join(m.dir, file))
+	}
+	return paths
+}
+
+// Restore writes back the original contents of the files rewritten by Apply
+func (m *ModuleRewrite) Restore() error {
+	for path, content := range m.original {
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("could not restore %s: %s", path, err.Error())
+		}
+	}
+	m.original = nil
+	return nil
+}
+
+// apply lists the files referencing the module path, and rewrites them if
+// write is set. Vendored code, testdata, hidden directories and nested
+// modules are skipped
+func (m *ModuleRewrite) apply(write bool) ([]string, error) {
+
+	files := []string{}
+	err := filepath.Walk(m.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			name := info.Name()
+			if path != m.dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); path != m.dir && err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var rewrite func([]byte) ([]byte, error)
+		switch {
+		case path == filepath.Join(m.dir, "go.mod"):
+			rewrite = m.rewriteGoMod
+		case strings.HasSuffix(path, ".go"):
+			rewrite = func(content []byte) ([]byte, error) { return m.rewriteImports(path, content) }
+		default:
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated, err := rewrite(content)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(content, updated) {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(m.dir, path)
+		files = append(files, rel)
+		if write {
+			if m.original == nil {
+				m.original = map[string][]byte{}
+			}
+			m.original[path] = content
+			return ioutil.WriteFile(path, updated, info.Mode())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not update module path: %s", err.Error())
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// rewriteGoMod replaces the module path of go.mod
+func (m *ModuleRewrite) rewriteGoMod(content []byte) ([]byte, error) {
+
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			path := m.NewPath
+			if strings.HasPrefix(fields[1], `"`) {
+				path = strconv.Quote(path)
+			}
+			lines[i] = strings.Replace(line, fields[1], path, 1)
+			break
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// rewriteImports replaces the module path in the import declarations of a
+// Go file
+func (m *ModuleRewrite) rewriteImports(path string, content []byte) ([]byte, error) {
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace from the end, so that offsets stay valid
+	updated := append([]byte{}, content...)
+	for i := len(file.Imports) - 1; i >= 0; i-- {
+		spec := file.Imports[i]
+		imported, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || (imported != m.Path && !strings.HasPrefix(imported, m.Path+"/")) {
+			continue
+		}
+		start := fset.Position(spec.Path.Pos()).Offset
+		end := fset.Position(spec.Path.End()).Offset
+		literal := strconv.Quote(m.NewPath + strings.TrimPrefix(imported, m.Path))
+		updated = append(updated[:start], append([]byte(literal), updated[end:]...)...)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// writeFiles creates files with their content in dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModulePathMajor(t *testing.T) {
+
+	var tests = []struct {
+		path     string
+		major    int
+		expected string
+	}{
+		{"github.com/a/b", 0, "github.com/a/b"},
+		{"github.com/a/b", 1, "github.com/a/b"},
+		{"github.com/a/b", 2, "github.com/a/b/v2"},
+		{"github.com/a/b/v2", 2, "github.com/a/b/v2"},
+		{"github.com/a/b/v2", 3, "github.com/a/b/v3"},
+		{"github.com/a/b/v10", 11, "github.com/a/b/v11"},
+		{"github.com/a/v1", 2, "github.com/a/v1/v2"},
+		{"github.com/a/b.v2", 3, "github.com/a/b.v2/v3"},
+		{"gopkg.in/yaml.v2", 3, "gopkg.in/yaml.v3"},
+		{"gopkg.in/yaml.v3", 3, "gopkg.in/yaml.v3"},
+	}
+
+	for i, test := range tests {
+		if got := ModulePathMajor(test.path, test.major); got != test.expected {
+			t.Errorf("TestModulePathMajor: test %d failed: expected %s, got %s", i+1, test.expected, got)
+		}
+	}
+
+}
+
+func TestModuleRewrite(t *testing.T) {
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                   "module github.com/a/b // comment\n\ngo 1.21\n\nrequire github.com/a/bc v1.0.0\n",
+		"main.go":                  "package main\n\nimport (\n\t\"fmt\"\n\n\tx \"github.com/a/b/pkg\"\n\t\"github.com/a/bc\"\n)\n\n// github.com/a/b/pkg stays\nfunc main() { fmt.Println(x.A, bc.B, \"github.com/a/b\") }\n",
+		"pkg/pkg.go":               "package pkg\n\nimport \"github.com/a/b\"\n",
+		"pkg/doc.go":               "package pkg\n",
+		"vendor/x/x.go":            "package x\n\nimport \"github.com/a/b/pkg\"\n",
+		"testdata/t.go":            "package t\n\nimport \"github.com/a/b/pkg\"\n",
+		"nested/go.mod":            "module github.com/a/b/nested\n",
+		"nested/n.go":              "package nested\n\nimport \"github.com/a/b/pkg\"\n",
+		".hidden/h.go":             "package h\n\nimport \"github.com/a/b/pkg\"\n",
+		"README.md":                "go get github.com/a/b\n",
+		"pkg/internal/internal.go": "package internal\n\nimport _ \"github.com/a/b/pkg\"\n",
+	})
+
+	if path, err := ReadModulePath(dir); err != nil || path != "github.com/a/b" {
+		t.Fatalf("TestModuleRewrite: unexpected module path '%s' (%v)", path, err)
+	}
+	if path, err := ReadModulePath(t.TempDir()); err != nil || path != "" {
+		t.Errorf("TestModuleRewrite: expected no module path, got '%s' (%v)", path, err)
+	}
+
+	// Matching module paths need no rewrite
+	if rewrite, err := planModuleRewrite(dir, 1); err != nil || rewrite != nil {
+		t.Errorf("TestModuleRewrite: unexpected rewrite %+v (%v)", rewrite, err)
+	}
+
+	rewrite, err := planModuleRewrite(dir, 2)
+	if err != nil {
+		t.Fatalf("TestModuleRewrite: unexpected error: %s", err.Error())
+	}
+	expected := []string{"go.mod", "main.go", "pkg/internal/internal.go", "pkg/pkg.go"}
+	if rewrite.NewPath != "github.com/a/b/v2" || strings.Join(rewrite.Files, ",") != strings.Join(expected, ",") {
+		t.Fatalf("TestModuleRewrite: unexpected rewrite %+v", rewrite)
+	}
+
+	// Planning does not modify files
+	if content, _ := ioutil.ReadFile(filepath.Join(dir, "go.mod")); strings.Contains(string(content), "/v2") {
+		t.Errorf("TestModuleRewrite: go.mod was modified by planning")
+	}
+
+	if err := rewrite.Apply(); err != nil {
+		t.Fatalf("TestModuleRewrite: unexpected error: %s", err.Error())
+	}
+
+	contains := map[string]string{
+		"go.mod":        "module github.com/a/b/v2 // comment\n",
+		"main.go":       "\tx \"github.com/a/b/v2/pkg\"\n\t\"github.com/a/bc\"\n)\n\n// github.com/a/b/pkg stays\nfunc main() { fmt.Println(x.A, bc.B, \"github.com/a/b\") }",
+		"pkg/pkg.go":    "import \"github.com/a/b/v2\"\n",
+		"vendor/x/x.go": "import \"github.com/a/b/pkg\"\n",
+		"nested/n.go":   "import \"github.com/a/b/pkg\"\n",
+		"README.md":     "go get github.com/a/b\n",
+	}
+	for name, text := range contains {
+		content, _ := ioutil.ReadFile(filepath.Join(dir, name))
+		if !strings.Contains(string(content), text) {
+			t.Errorf("TestModuleRewrite: %s does not contain '%s':\n%s", name, text, content)
+		}
+	}
+	if path, _ := ReadModulePath(dir); path != "github.com/a/b/v2" {
+		t.Errorf("TestModuleRewrite: unexpected module path '%s' after rewrite", path)
+	}
+
+}
+
+func TestIncreaseGoMod(t *testing.T) {
+
+	color.NoColor = true
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":  "module github.com/a/b\n",
+		"main.go": "package main\n\nimport _ \"github.com/a/b/pkg\"\n",
+	})
+
+	repo := newFakeRepository(dir)
+	repo.commit("a")
+	repo.tag("v1.2.0")
+	repo.commit("b")
+
+	// Minor and patch increases ignore the module path
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Minor: true, DryRun: true}, strings.NewReader(""), out); err != nil {
+		t.Errorf("TestIncreaseGoMod: unexpected error: %s", err.Error())
+	}
+
+	// Major increases require the matching suffix
+	err := Increase(repo, &IncreaseOptions{Major: true, Yes: true}, strings.NewReader(""), out)
+	if err == nil || !strings.Contains(err.Error(), "use --go-mod to change it to github.com/a/b/v2") {
+		t.Errorf("TestIncreaseGoMod: expected a module path error, got %v", err)
+	}
+
+	repo.dirty = true
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, Yes: true}, strings.NewReader(""), out); err == nil || !strings.Contains(err.Error(), "commit or stash") {
+		t.Errorf("TestIncreaseGoMod: expected a dirty working tree error, got %v", err)
+	}
+	repo.dirty = false
+
+	// Only tags are pushed, not the module path commit
+	err = Increase(repo, &IncreaseOptions{Major: true, GoMod: true, Push: true, Yes: true}, strings.NewReader(""), out)
+	if _, ok := err.(*UsageError); !ok || !strings.Contains(err.Error(), "cannot push a tag on a new module path commit") {
+		t.Errorf("TestIncreaseGoMod: expected a push usage error, got %v", err)
+	}
+
+	// Dry runs list the files
+	out.Reset()
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, DryRun: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseGoMod: unexpected error: %s", err.Error())
+	}
+	for _, text := range []string{"github.com/a/b -> github.com/a/b/v2", "go.mod", "main.go", "on a new commit on top of " + repo.head} {
+		if !strings.Contains(out.String(), text) {
+			t.Errorf("TestIncreaseGoMod: dry run does not contain '%s':\n%s", text, out.String())
+		}
+	}
+	if path, _ := ReadModulePath(dir); path != "github.com/a/b" {
+		t.Errorf("TestIncreaseGoMod: dry run modified go.mod")
+	}
+
+	// The rewrite is committed and tagged
+	head := repo.head
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, Yes: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseGoMod: unexpected error: %s", err.Error())
+	}
+	commit, _ := repo.Head()
+	if repo.parents[commit.Hash] != head || commit.Message != "chore: update module path to github.com/a/b/v2" || commit.Body != filepath.Join(dir, "go.mod")+"\n"+filepath.Join(dir, "main.go") {
+		t.Errorf("TestIncreaseGoMod: unexpected commit %+v", commit)
+	}
+	if tag := repo.tags[len(repo.tags)-1]; tag.Name != "v2.0.0" || tag.Commit != commit.Hash {
+		t.Errorf("TestIncreaseGoMod: unexpected tag %+v", tag)
+	}
+	if content, _ := ioutil.ReadFile(filepath.Join(dir, "main.go")); !strings.Contains(string(content), "github.com/a/b/v2/pkg") {
+		t.Errorf("TestIncreaseGoMod: imports were not rewritten:\n%s", content)
+	}
+
+	// Following major increases keep the suffix up to date
+	repo.commit("c")
+	out.Reset()
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, DryRun: true}, strings.NewReader(""), out); err != nil || !strings.Contains(out.String(), "github.com/a/b/v2 -> github.com/a/b/v3") {
+		t.Errorf("TestIncreaseGoMod: unexpected dry run (%v):\n%s", err, out.String())
+	}
+
+	// Files of components are relative to the module of the component
+	writeFiles(t, dir, map[string]string{
+		"sub/go.mod": "module github.com/a/b/sub\n",
+		"sub/sub.go": "package sub\n\nimport _ \"github.com/a/b/sub/pkg\"\n",
+	})
+	repo.tag("sub/v1.0.0")
+	repo.commit("d")
+
+	// Failed commits restore the rewritten files and create no tag
+	tags := len(repo.tags)
+	repo.failure = fmt.Errorf("commit failed")
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, Component: "sub", Yes: true}, strings.NewReader(""), out); err == nil || !strings.Contains(err.Error(), "commit failed") {
+		t.Errorf("TestIncreaseGoMod: expected a commit error, got %v", err)
+	}
+	repo.failure = nil
+	if path, _ := ReadModulePath(filepath.Join(dir, "sub")); path != "github.com/a/b/sub" || len(repo.tags) != tags {
+		t.Errorf("TestIncreaseGoMod: failed commit left module path %s and %d new tags", path, len(repo.tags)-tags)
+	}
+
+	head = repo.head
+	if err := Increase(repo, &IncreaseOptions{Major: true, GoMod: true, Component: "sub", Yes: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseGoMod: unexpected error: %s", err.Error())
+	}
+	commit, _ = repo.Head()
+	if repo.parents[commit.Hash] != head || commit.Body != filepath.Join(dir, "sub", "go.mod")+"\n"+filepath.Join(dir, "sub", "sub.go") {
+		t.Errorf("TestIncreaseGoMod: unexpected commit %+v", commit)
+	}
+	if tag := repo.tags[len(repo.tags)-1]; tag.Name != "sub/v2.0.0" || tag.Commit != commit.Hash {
+		t.Errorf("TestIncreaseGoMod: unexpected tag %+v", tag)
+	}
+	if path, _ := ReadModulePath(filepath.Join(dir, "sub")); path != "github.com/a/b/sub/v2" {
+		t.Errorf("TestIncreaseGoMod: unexpected module path %s of the component", path)
+	}
+
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
-	"os/exec"
+	"os/signal"
+	"runtime"
 	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/vaitekunas/version/semver"
+)
+
+// Exit codes
+const (
+	ExitSuccess  = 0 // command succeeded
+	ExitFailure  = 1 // command failed
+	ExitUsage    = 2 // invalid arguments (flag package default)
+	ExitAborted  = 3 // version increase declined by the user
+	ExitDiverged = 4 // local and remote versions differ
+	ExitNoMatch  = 5 // no version satisfies the constraint
 )
 
 func init() {
-	_, err := exec.LookPath("git")
-	if err != nil {
-		printErr("Git not found. Cannot proceed, sorry")
-		os.Exit(1)
-	}
 	flag.Usage = help
 }
 
@@ -27,10 +37,90 @@ func main() {
 	patchPtr := incCmd.Bool("patch", false, "increase patch version")
 	specialPtr := incCmd.String("special", "", "set pre-release version ")
 	buildPtr := incCmd.String("build", "", "set build metadata")
+	autoPtr := incCmd.Bool("auto", false, "derive the tick from conventional commits")
+	messagePtr := incCmd.String("message", "", "tag annotation")
+	messageFilePtr := incCmd.String("message-file", "", "file containing the tag annotation")
+	notesPtr := incCmd.Bool("notes", false, "append release notes to the tag annotation")
+	editPtr := incCmd.Bool("edit", false, "edit the tag annotation before tagging")
+	signPtr := incCmd.Bool("sign", false, "sign the tag")
+	signKeyPtr := incCmd.String("sign-key", "", "key used to sign the tag (implies --sign)")
+	signFormatPtr := incCmd.String("sign-format", "", "signing format (openpgp, ssh)")
+	pushPtr := incCmd.Bool("push", false, "push the new tag")
+	remotePtr := incCmd.String("remote", "origin", "remote the new tag is pushed to")
+	goModPtr := incCmd.Bool("go-mod", false, "add the major version suffix to the Go module path")
+	componentPtr := incCmd.String("component", "", "monorepo component whose version is increased")
+	tagSchemePtr := incCmd.String("tag-scheme", "", "tag scheme of component versions (path, at)")
+	incBackendPtr := incCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	incYesPtr := incCmd.Bool("yes", false, "tag without asking for confirmation")
+	incNonInteractivePtr := incCmd.Bool("non-interactive", false, "same as --yes")
+	incDryRunPtr := incCmd.Bool("dry-run", false, "print the tag that would be created without tagging")
+	incFormatPtr := incCmd.String("format", FormatTable, "format of the dry run plan (table, json)")
+	incTemplatePtr := incCmd.String("template", "", "template rendered after the version is tagged")
+	incTemplateFilePtr := incCmd.String("template-file", "", "file containing the template rendered after the version is tagged")
+
+	// Changelog flags
+	changelogCmd := flag.NewFlagSet("changelog", flag.ExitOnError)
+	fromPtr := changelogCmd.String("from", "", "version the changelog starts after")
+	toPtr := changelogCmd.String("to", "", "last version included in the changelog")
+	prependPtr := changelogCmd.String("prepend", "", "Keep-a-Changelog file to add the section to")
+	changelogBackendPtr := changelogCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+
+	// Remote version list flags
+	remoteCmd := flag.NewFlagSet("remote", flag.ExitOnError)
+	remoteAllPtr := remoteCmd.Bool("all", false, "show all versions")
+	remoteTiebreakPtr := remoteCmd.String("tiebreak", string(TieBreakNone), "order of versions differing only in build metadata (none, commit-date, tag-date, build)")
+	remoteBackendPtr := remoteCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	remoteFormatPtr := remoteCmd.String("format", FormatTable, "output format (table, json, yaml, csv, tsv, markdown, plain)")
+	remoteTemplatePtr := remoteCmd.String("template", "", "template rendering every listed version")
+	remoteTemplateFilePtr := remoteCmd.String("template-file", "", "file containing the template rendering every listed version")
+
+	// Version status flags
+	statusCmd := flag.NewFlagSet("status", flag.ExitOnError)
+	statusRemotePtr := statusCmd.String("remote", "origin", "remote the versions are compared with")
+	statusAllPtr := statusCmd.Bool("all", false, "also list synced versions")
+	statusBackendPtr := statusCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	statusFormatPtr := statusCmd.String("format", FormatTable, "output format (table, json)")
+
+	// Next version flags
+	nextCmd := flag.NewFlagSet("next", flag.ExitOnError)
+	nextMajorPtr := nextCmd.Bool("major", false, "increase major version")
+	nextMinorPtr := nextCmd.Bool("minor", false, "increase minor version")
+	nextPatchPtr := nextCmd.Bool("patch", false, "increase patch version")
+	nextAutoPtr := nextCmd.Bool("auto", false, "derive the tick from conventional commits")
+	nextSpecialPtr := nextCmd.String("special", "", "set pre-release version")
+	nextBuildPtr := nextCmd.String("build", "", "set build metadata")
+	nextComponentPtr := nextCmd.String("component", "", "monorepo component whose next version is computed")
+	nextTagSchemePtr := nextCmd.String("tag-scheme", "", "tag scheme of component versions (path, at)")
+	nextBackendPtr := nextCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	nextFormatPtr := nextCmd.String("format", "", "output format (json), the version only if empty")
+
+	// Current version flags
+	currentCmd := flag.NewFlagSet("current", flag.ExitOnError)
+	currentPseudoPtr := currentCmd.Bool("pseudo", false, "print the pseudo-version")
+	currentComponentPtr := currentCmd.String("component", "", "monorepo component whose version is described")
+	currentBackendPtr := currentCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	currentFormatPtr := currentCmd.String("format", "", "output format (json), a single line if empty")
+
+	// Version match flags
+	matchCmd := flag.NewFlagSet("match", flag.ExitOnError)
+	matchHighestPtr := matchCmd.Bool("highest", false, "show only the highest matching version")
+	matchPrereleasePtr := matchCmd.Bool("prerelease", false, "let pre-releases satisfy ranges like releases")
+	matchComponentPtr := matchCmd.String("component", "", "monorepo component whose versions are matched")
+	matchTiebreakPtr := matchCmd.String("tiebreak", string(TieBreakNone), "order of versions differing only in build metadata (none, commit-date, tag-date, build)")
+	matchBackendPtr := matchCmd.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	matchFormatPtr := matchCmd.String("format", "", "output format (table, json, yaml, csv, tsv, markdown, plain), tags only if empty")
 
 	// Version list flags
 	listRootPtr := flag.String("root", "", "root path where listing version should start")
 	listallPtr := flag.Bool("all", false, "show all versions")
+	tiebreakPtr := flag.String("tiebreak", string(TieBreakNone), "order of versions differing only in build metadata (none, commit-date, tag-date, build)")
+	backendPtr := flag.String("backend", BackendAuto, "git backend (auto, exec, go-git)")
+	formatPtr := flag.String("format", FormatTable, "output format (table, json, yaml, csv, tsv, markdown, plain)")
+	templatePtr := flag.String("template", "", "template rendering every listed version")
+	templateFilePtr := flag.String("template-file", "", "file containing the template rendering every listed version")
+	jobsPtr := flag.Int("jobs", runtime.NumCPU(), "number of repositories scanned concurrently")
+	verifyPtr := flag.Bool("verify", false, "check the signatures of version tags")
+	tagInfoPtr := flag.Bool("tag-info", false, "show the kind, tagger, date and message of version tags")
 
 	// Parse subcommand flags
 	if len(os.Args) > 1 {
@@ -39,25 +129,293 @@ func main() {
 		case "increase":
 			incCmd.Parse(os.Args[2:])
 
+		case "changelog":
+			changelogCmd.Parse(os.Args[2:])
+
+		case "remote":
+			remoteCmd.Parse(os.Args[2:])
+
+		case "status":
+			statusCmd.Parse(os.Args[2:])
+
+		case "match":
+			matchCmd.Parse(os.Args[2:])
+
+		case "next":
+			nextCmd.Parse(os.Args[2:])
+
+		case "current":
+			currentCmd.Parse(os.Args[2:])
+
 		case "help":
 			if len(os.Args) >= 3 {
 				man(os.Args[2])
 			} else {
 				man("")
 			}
-			os.Exit(0)
+			os.Exit(ExitSuccess)
 
 		case "--help":
 			flag.Usage()
-			os.Exit(1)
+			os.Exit(ExitFailure)
 		}
 
 		// Increase version
 		if incCmd.Parsed() {
-			if err := Increase(*majorPtr, *minorPtr, *patchPtr, *specialPtr, *buildPtr); err != nil {
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *incBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			tmpl, err := ReadTemplate(*incTemplatePtr, *incTemplateFilePtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			message, err := ReadMessage(*messagePtr, *messageFilePtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &IncreaseOptions{
+				Major:      *majorPtr,
+				Minor:      *minorPtr,
+				Patch:      *patchPtr,
+				Special:    *specialPtr,
+				Build:      *buildPtr,
+				Auto:       *autoPtr,
+				Message:    message,
+				Notes:      *notesPtr,
+				Edit:       *editPtr,
+				Sign:       *signPtr,
+				SignKey:    *signKeyPtr,
+				SignFormat: *signFormatPtr,
+				Push:       *pushPtr,
+				Remote:     *remotePtr,
+				GoMod:      *goModPtr,
+				Component:  strings.Trim(*componentPtr, "/"),
+				TagScheme:  *tagSchemePtr,
+				Template:   tmpl,
+				Yes:        *incYesPtr || *incNonInteractivePtr,
+				DryRun:     *incDryRunPtr,
+				Format:     strings.ToLower(*incFormatPtr),
+			}
+			if err := Increase(repo, opts, os.Stdin, os.Stdout); err == ErrAborted {
+				os.Exit(ExitAborted)
+			} else if _, ok := err.(*UsageError); ok {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitUsage)
+			} else if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+
+		// Render changelog
+		if changelogCmd.Parsed() {
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *changelogBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &ChangelogOptions{
+				From:    *fromPtr,
+				To:      *toPtr,
+				Prepend: *prependPtr,
+			}
+			if err := WriteChangelog(repo, opts, os.Stdout); err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+		// List remote versions
+		if remoteCmd.Parsed() {
+			if remoteCmd.NArg() != 1 {
+				printErr("FAILED: expected a single remote name or URL")
+				os.Exit(ExitUsage)
+			}
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *remoteBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			tiebreak, err := ParseTieBreak(*remoteTiebreakPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			format, err := ParseFormat(*remoteFormatPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			tmpl, err := ReadTemplate(*remoteTemplatePtr, *remoteTemplateFilePtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &ListOptions{
+				All:      *remoteAllPtr,
+				TieBreak: tiebreak,
+				Format:   format,
+				Template: tmpl,
+			}
+			if err := ListRemote(repo, remoteCmd.Arg(0), opts, os.Stdout); err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+
+		// Compare local and remote versions
+		if statusCmd.Parsed() {
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *statusBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &StatusOptions{
+				Remote: *statusRemotePtr,
+				All:    *statusAllPtr,
+				Format: strings.ToLower(*statusFormatPtr),
+			}
+			if err := Status(repo, opts, os.Stdout); err == ErrDiverged {
+				os.Exit(ExitDiverged)
+			} else if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+
+		// Print the next version
+		if nextCmd.Parsed() {
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *nextBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &IncreaseOptions{
+				Major:     *nextMajorPtr,
+				Minor:     *nextMinorPtr,
+				Patch:     *nextPatchPtr,
+				Auto:      *nextAutoPtr,
+				Special:   *nextSpecialPtr,
+				Build:     *nextBuildPtr,
+				Component: strings.Trim(*nextComponentPtr, "/"),
+				TagScheme: *nextTagSchemePtr,
+				Format:    strings.ToLower(*nextFormatPtr),
+			}
+			err = Next(repo, opts, os.Stdout)
+			if _, ok := err.(*UsageError); ok {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitUsage)
+			} else if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+
+		// Describe the version of HEAD
+		if currentCmd.Parsed() {
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			repo, err := OpenRepository(root, *currentBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			opts := &CurrentOptions{
+				Component: strings.Trim(*currentComponentPtr, "/"),
+				Pseudo:    *currentPseudoPtr,
+				Format:    strings.ToLower(*currentFormatPtr),
+			}
+			if err := Current(repo, opts, os.Stdout); err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
+		}
+
+		// Match versions against a constraint
+		if matchCmd.Parsed() {
+			if matchCmd.NArg() != 1 {
+				printErr("FAILED: expected a single constraint, e.g. '>=1.2.0 <2.0.0'")
+				os.Exit(ExitUsage)
+			}
+			constraint, err := semver.ParseConstraint(matchCmd.Arg(0))
+			if err != nil {
 				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitUsage)
+			}
+			constraint.IncludePrerelease = *matchPrereleasePtr
+			root, err := os.Getwd()
+			if err != nil {
+				printErr("FAILED: could not determine current directory: %s", err.Error())
+				os.Exit(ExitFailure)
 			}
-			os.Exit(0)
+			repo, err := OpenRepository(root, *matchBackendPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			tiebreak, err := ParseTieBreak(*matchTiebreakPtr)
+			if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			format := ""
+			if *matchFormatPtr != "" {
+				if format, err = ParseFormat(*matchFormatPtr); err != nil {
+					printErr("FAILED: %s", err.Error())
+					os.Exit(ExitFailure)
+				}
+			}
+			opts := &MatchOptions{
+				Constraint: constraint,
+				Component:  strings.Trim(*matchComponentPtr, "/"),
+				Highest:    *matchHighestPtr,
+				TieBreak:   tiebreak,
+				Format:     format,
+			}
+			if err := Match(repo, opts, os.Stdout); err == ErrNoMatch {
+				os.Exit(ExitNoMatch)
+			} else if err != nil {
+				printErr("FAILED: %s", err.Error())
+				os.Exit(ExitFailure)
+			}
+			os.Exit(ExitSuccess)
 		}
 	}
 
@@ -65,8 +423,42 @@ func main() {
 	flag.Parse()
 
 	// List versions
-	if err := List(strings.TrimRight(*listRootPtr, "/"), *listallPtr); err != nil {
+	tiebreak, err := ParseTieBreak(*tiebreakPtr)
+	if err != nil {
+		printErr("FAILED: %s", err.Error())
+		os.Exit(ExitFailure)
+	}
+	format, err := ParseFormat(*formatPtr)
+	if err != nil {
+		printErr("FAILED: %s", err.Error())
+		os.Exit(ExitFailure)
+	}
+	tmpl, err := ReadTemplate(*templatePtr, *templateFilePtr)
+	if err != nil {
+		printErr("FAILED: %s", err.Error())
+		os.Exit(ExitFailure)
+	}
+	opts := &ListOptions{
+		All:      *listallPtr,
+		TieBreak: tiebreak,
+		Backend:  *backendPtr,
+		Jobs:     *jobsPtr,
+		Verify:   *verifyPtr,
+		TagInfo:  *tagInfoPtr,
+		Format:   format,
+		Template: tmpl,
+	}
+	if isatty.IsTerminal(os.Stderr.Fd()) {
+		opts.Progress = os.Stderr
+	}
+
+	// Stop scanning on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := List(ctx, strings.TrimRight(*listRootPtr, "/"), opts, os.Stdout); err != nil {
 		printErr("FAILED: %s", err.Error())
+		os.Exit(ExitFailure)
 	}
 
 }
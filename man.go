@@ -19,9 +19,15 @@ func help() {
 	fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint(os.Args[0]))
 	fmt.Fprintf(os.Stderr, "version [command] [arguments]\n\n")
 	fmt.Fprintf(os.Stderr, "The commands are:\n\n")
-	fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("increase"), "increases the version by a major/minor/patch tick\n"))
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("increase"), "increases the version by a major/minor/patch tick\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("changelog"), "renders the changes between two versions as Markdown\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("remote"), "lists the versions of a remote without fetching its tags\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("status"), "compares local version tags with those of a remote\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("next"), "prints the version an increase would tag, without tagging\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("current"), "describes the version of HEAD, e.g. v1.2.3-5-gabc1234-dirty\n")
+	fmt.Fprintf(os.Stderr, "\t%s\t%s", out("match"), "lists the versions satisfying a constraint, e.g. '^1.2'\n")
 	fmt.Fprintf(os.Stderr, "\n")
-	fmt.Fprintf(os.Stderr, "Using \"version [--root=\"\"] [--all]\" lists available releases/versions\n")
+	fmt.Fprintf(os.Stderr, "Using \"version [--root=\"\"] [--all] [--tiebreak=\"\"] [--jobs=N]\" lists available releases/versions\n")
 	fmt.Fprintf(os.Stderr, "Use \"version help [command]\" for more information about a command\n\n")
 
 }
@@ -36,27 +42,152 @@ func man(cmd string) {
 
 	case "increase":
 		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version increase"))
-		fmt.Fprintf(os.Stderr, "version increase [{--major, --minor, --patch}] [--special=\"\"] [--build=\"\"]\n\n")
+		fmt.Fprintf(os.Stderr, "version increase [{--major, --minor, --patch, --auto}] [--special=\"\"] [--build=\"\"] [--message=\"\"] [--message-file=\"\"] [--notes] [--edit] [--sign] [--sign-key=\"\"] [--sign-format=\"\"] [--push] [--remote=\"\"] [--go-mod] [--component=\"\"] [--tag-scheme=\"\"] [--yes] [--dry-run] [--format=\"\"] [--backend=\"\"] [--template=\"\"] [--template-file=\"\"]\n\n")
 		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--major"), "increase version by a major tick\n"))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--minor"), "increase version by a minor tick\n"))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--patch"), "increase version by a patch tick\n"))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--special"), "specify pre-release version\n"))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--build"), "add build-related metadata\n"))
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--major"), "increase version by a major tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--minor"), "increase version by a minor tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--patch"), "increase version by a patch tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--auto"), "derive the tick from conventional commits since the current version\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--special"), "specify pre-release version\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--build"), "add build-related metadata\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--message"), "tag annotation (default: \"Version vX.Y.Z\")\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--message-file"), "file containing the tag annotation\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--notes"), "append release notes (commits since the current version) to the tag annotation\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--edit"), "edit the tag annotation in $VISUAL/$EDITOR before tagging\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--sign"), "sign the tag with git's default key (exec backend only)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--sign-key"), "GPG key id or SSH key file used to sign the tag (implies --sign)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--sign-format"), "signing format: openpgp or ssh (default: git's gpg.format)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--push"), "push the new tag (and only the new tag) to the remote\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--remote"), "remote the new tag is pushed to (default: origin)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--go-mod"), "rewrite go.mod and imports to the /vN module path of a new major version and commit them before tagging\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--component"), "monorepo component whose version series is increased, e.g. sub/dir\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tag-scheme"), "tag scheme of components: path (sub/dir/v1.2.3, default) or at (component@1.2.3)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--yes"), "tag without asking for confirmation (alias: --non-interactive)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--dry-run"), "print the tag that would be created on which commit, without tagging\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "format of the dry run plan: table (default) or json\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template"), "Go template rendered after tagging, e.g. 'Released {{.Version}}'\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template-file"), "file containing the Go template\n")
 		fmt.Fprintf(os.Stderr, "\n")
-		fmt.Fprintf(os.Stderr, "Only a single tick option (major/minor/patch) is allowed per increase\n")
+		fmt.Fprintf(os.Stderr, "Only a single tick option (major/minor/patch/auto) is allowed per increase\n")
+		fmt.Fprintf(os.Stderr, "With --auto breaking changes bump major (minor for 0.y.z versions), feat commits minor and fix commits patch\n")
+		fmt.Fprintf(os.Stderr, "With --auto and a --component of the path scheme only commits changing files of its directory are considered\n")
 		fmt.Fprintf(os.Stderr, "Setting special and build identifiers without tick updates will use the current version\n")
-		fmt.Fprintf(os.Stderr, "Build metadata cannot be added to a release version, i.e. a pre-release version must be always specified\n")
+		fmt.Fprintf(os.Stderr, "Build metadata can be added to release and pre-release versions alike, e.g. v1.2.3+build or v1.2.3-rc.1+build\n")
+		fmt.Fprintf(os.Stderr, "Special and build identifiers must follow semver ([0-9A-Za-z-] separated by dots), otherwise the command exits with code 2\n")
 		fmt.Fprintf(os.Stderr, "Command will fail when attempting to set a version that is smaller than the current version\n")
+		fmt.Fprintf(os.Stderr, "Command fails when stdin is not a terminal, unless --yes is used\n")
+		fmt.Fprintf(os.Stderr, "Major increases to v2 or higher fail when the module path in go.mod lacks the /vN suffix, unless --go-mod is used\n")
+		fmt.Fprintf(os.Stderr, "--go-mod cannot be combined with --push when the module path is rewritten: push the branch and the tag yourself\n")
+		fmt.Fprintf(os.Stderr, "The default tag scheme of components can be set with the git configuration version.tagScheme\n")
+		fmt.Fprintf(os.Stderr, "When the push is rejected, the local tag is deleted and the command fails\n")
+		fmt.Fprintf(os.Stderr, "Tags are signed by default when the git configuration sets version.sign (and optionally version.signKey, version.signFormat)\n")
+		fmt.Fprintf(os.Stderr, "Exit codes: 0 - version tagged, 1 - failure, 3 - aborted by the user\n")
 		fmt.Fprintf(os.Stderr, "Using \"version increase\" will bump the repository in pwd by a patch tick\n\n")
 
+	case "changelog":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version changelog"))
+		fmt.Fprintf(os.Stderr, "version changelog [--from=\"\"] [--to=\"\"] [--prepend=\"\"] [--backend=\"\"]\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--from"), "version the changelog starts after (default: the version preceding --to)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--to"), "last version included in the changelog (default: version of HEAD, or its unreleased changes)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--prepend"), "Keep-a-Changelog file to add the section to, e.g. CHANGELOG.md\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "Commits are grouped by their conventional commit type: breaking changes, features, fixes and other\n")
+		fmt.Fprintf(os.Stderr, "Pre-releases are skipped when looking for the version preceding a release\n")
+		fmt.Fprintf(os.Stderr, "An existing unreleased section is replaced, also by a new release, released sections are never overwritten\n\n")
+
+	case "remote":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version remote"))
+		fmt.Fprintf(os.Stderr, "version remote [--all] [--tiebreak=\"\"] [--backend=\"\"] [--format=\"\"] [--template=\"\"] [--template-file=\"\"] <name|url>\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--all"), "list all versions\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tiebreak"), "order of versions differing only in build metadata: none (default, git's order), commit-date, tag-date or build\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "output format: table (default), json, yaml, csv, tsv, markdown or plain\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template"), "Go template rendering every version, e.g. '{{.Repo}} {{.Version}}'\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template-file"), "file containing the Go template\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "The remote is either the name of a remote of the repository in pwd or a URL\n")
+		fmt.Fprintf(os.Stderr, "URLs can be listed outside of a repository with the exec backend only\n")
+		fmt.Fprintf(os.Stderr, "Tags are read from the remote's ref advertisement (like git ls-remote), nothing is fetched\n")
+		fmt.Fprintf(os.Stderr, "Commit dates are only shown for commits that exist locally\n\n")
+
+	case "status":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version status"))
+		fmt.Fprintf(os.Stderr, "version status [--remote=\"\"] [--all] [--backend=\"\"] [--format=\"\"]\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--remote"), "remote name or URL the versions are compared with (default: origin)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--all"), "also list versions that are in sync\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "output format: table (default) or json\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "Version tags are local-only (never pushed), remote-only (not fetched) or diverged (different commits)\n")
+		fmt.Fprintf(os.Stderr, "Exit codes: 0 - in sync, 1 - failure, 4 - local and remote versions differ\n\n")
+
+	case "next":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version next"))
+		fmt.Fprintf(os.Stderr, "version next [{--major, --minor, --patch, --auto}] [--special=\"\"] [--build=\"\"] [--component=\"\"] [--tag-scheme=\"\"] [--backend=\"\"] [--format=\"\"]\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--major"), "increase version by a major tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--minor"), "increase version by a minor tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--patch"), "increase version by a patch tick\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--auto"), "derive the tick from conventional commits since the current version\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--special"), "specify pre-release version\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--build"), "add build-related metadata\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--component"), "monorepo component whose next version is computed\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tag-scheme"), "tag scheme of components: path (sub/dir/v1.2.3, default) or at (component@1.2.3)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "json prints the current and the next version, the tag and the applied tick (default: the version only)\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "The next version is computed like \"version increase\" does, nothing is tagged\n")
+		fmt.Fprintf(os.Stderr, "Without a tick a patch version update is computed\n\n")
+
+	case "current":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version current"))
+		fmt.Fprintf(os.Stderr, "version current [--pseudo] [--component=\"\"] [--backend=\"\"] [--format=\"\"]\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--pseudo"), "print a semver-valid pseudo-version, e.g. v1.2.4-0.20261018120000-abc123def456\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--component"), "monorepo component whose version is described (default: the repository's own versions)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "json prints the version, distance, commit, dirty state, description and pseudo-version (default: a single line)\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "A tagged HEAD is described by its version, otherwise by the highest reachable version, the number of commits since and the abbreviated hash\n")
+		fmt.Fprintf(os.Stderr, "Modified tracked files add -dirty (+dirty to pseudo-versions)\n\n")
+
+	case "match":
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version match"))
+		fmt.Fprintf(os.Stderr, "version match [--highest] [--prerelease] [--component=\"\"] [--tiebreak=\"\"] [--backend=\"\"] [--format=\"\"] <constraint>\n\n")
+		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--highest"), "show only the highest matching version\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--prerelease"), "let pre-releases satisfy ranges like releases\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--component"), "monorepo component whose versions are matched (default: the repository's own versions)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tiebreak"), "order of versions differing only in build metadata: none (default, git's order), commit-date, tag-date or build\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "output format: table, json, yaml, csv, tsv, markdown or plain (default: tags only, one per line)\n")
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "Constraints use npm's syntax: ranges separated by || consist of comparators, all of which must be satisfied\n")
+		fmt.Fprintf(os.Stderr, "Comparators: 1.2.3, =1.2.3, >1.2.3, >=1.2.3, <1.2.3, <=1.2.3, ~1.2.3, ^1.2.3, wildcards (1.2.x, 1.*, 1, *) and hyphen ranges (1.2.3 - 2.3)\n")
+		fmt.Fprintf(os.Stderr, "Pre-releases only match ranges containing a pre-release of the same version, unless --prerelease is given\n")
+		fmt.Fprintf(os.Stderr, "Exit codes: 0 - match found, 1 - failure, 2 - invalid constraint, 5 - no version satisfies the constraint\n\n")
+
 	case "":
 		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n\n", b.Sprint("version"))
-		fmt.Fprintf(os.Stderr, "version [--root=\"\"] [--all]\n\n")
+		fmt.Fprintf(os.Stderr, "version [--root=\"\"] [--all] [--tiebreak=\"\"] [--backend=\"\"] [--jobs=N] [--format=\"\"] [--verify] [--tag-info] [--template=\"\"] [--template-file=\"\"]\n\n")
 		fmt.Fprintf(os.Stderr, "The arguments are:\n\n")
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--root"), "root path from which to start listing repositories and their versions\n"))
-		fmt.Fprintf(os.Stderr, fmt.Sprintf("\t%s\t%s", out("--all"), "list all versions\n"))
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--root"), "root path from which to start listing repositories and their versions\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--all"), "list all versions\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tiebreak"), "order of versions differing only in build metadata: none (default, git's order), commit-date, tag-date or build\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--backend"), "git backend: auto (default), exec or go-git\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--jobs"), "number of repositories scanned concurrently (default: number of CPUs)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--format"), "output format: table (default), json, yaml, csv, tsv, markdown or plain\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--verify"), "show the signature state of every version, fail on invalid signatures\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--tag-info"), "show whether tags are annotated or lightweight, their tagger, tag date and message (its first line in tables)\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template"), "Go template rendering every version, e.g. '{{.Repo}} {{.Version}}'\n")
+		fmt.Fprintf(os.Stderr, "\t%s\t%s", out("--template-file"), "file containing the Go template\n")
 		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprintf(os.Stderr, "Tags prefixed by a component (sub/dir/v1.2.3 or component@1.2.3) form version series of their own\n")
 		fmt.Fprintf(os.Stderr, "Using \"version\" will display the current version of the repository in pwd\n")
 		fmt.Fprintf(os.Stderr, "Specifying a directory will recursively display the version(s) of the repositories contained there\n\n")
 
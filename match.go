@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vaitekunas/version/semver"
+)
+
+// ErrNoMatch is returned by Match if no version satisfies the constraint
+var ErrNoMatch = errors.New("no version satisfies the constraint")
+
+// MatchOptions holds the parameters of a constraint match
+type MatchOptions struct {
+	Constraint *semver.Constraint
+	Component  string // only versions of this component are matched, see SplitTag
+	Highest    bool   // show only the highest matching version
+	TieBreak   TieBreak
+	Format     string // output format, see ParseFormat. Only tags are printed if empty
+}
+
+// Match prints the versions of a repository satisfying a constraint,
+// ordered from the highest to the lowest version. Without a format, the
+// tags are printed one per line. Returns ErrNoMatch if there are none
+func Match(repo Repository, opts *MatchOptions, w io.Writer) error {
+
+	versions, err := GetVersions(repo, opts.TieBreak)
+	if err != nil {
+		return err
+	}
+
+	matching := versions.Component(opts.Component).Match(opts.Constraint)
+	if matching.Len() == 0 {
+		return ErrNoMatch
+	}
+
+	if opts.Format != "" {
+		repoVersions := map[string]*Versions{repo.Path(): matching}
+		return printVersions(w, opts.Format, []string{repo.Path()}, repoVersions, opts.Highest, false)
+	}
+
+	for _, version := range matching.versions {
+		if _, err := fmt.Fprintln(w, version.Tag); err != nil {
+			return err
+		}
+		if opts.Highest {
+			break
+		}
+	}
+
+	return nil
+}
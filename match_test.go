@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/vaitekunas/version/semver"
+)
+
+func TestMatch(t *testing.T) {
+
+	repo := newFakeRepository("/match")
+	for _, tag := range []string{"v1.1.0", "v1.2.0", "v1.4.2", "v2.0.0-rc.1", "v2.0.0", "v2.3.1", "v2.4.0", "api/v1.9.0"} {
+		repo.commit(tag)
+		repo.tag(tag)
+	}
+
+	tests := []struct {
+		constraint string
+		opts       MatchOptions
+		expected   string
+	}{
+		{">=1.2.0 <2.0.0 || ~2.3", MatchOptions{}, "v2.3.1 v1.4.2 v1.2.0"},
+		{"^1", MatchOptions{Highest: true}, "v1.4.2"},
+		{"^2.0.0-rc.1", MatchOptions{}, "v2.4.0 v2.3.1 v2.0.0 v2.0.0-rc.1"},
+		{"2.x", MatchOptions{}, "v2.4.0 v2.3.1 v2.0.0"},
+		{"2.x", MatchOptions{Highest: true, Component: "api"}, ""},
+		{"*", MatchOptions{Component: "api"}, "api/v1.9.0"},
+		{"<2", MatchOptions{}, "v1.4.2 v1.2.0 v1.1.0"},
+		{">=2.0.0-0 <2.0.1", MatchOptions{}, "v2.0.0 v2.0.0-rc.1"},
+	}
+
+	for i, test := range tests {
+		opts := test.opts
+		opts.Constraint = semver.MustParseConstraint(test.constraint)
+
+		out := &bytes.Buffer{}
+		err := Match(repo, &opts, out)
+		if test.expected == "" {
+			if err != ErrNoMatch {
+				t.Errorf("TestMatch: test %d failed: expected ErrNoMatch, got %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestMatch: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		if got := strings.Join(strings.Fields(out.String()), " "); got != test.expected {
+			t.Errorf("TestMatch: test %d failed: got '%s', expected '%s'", i+1, got, test.expected)
+		}
+	}
+
+	// Machine-readable output
+	out := &bytes.Buffer{}
+	opts := &MatchOptions{Constraint: semver.MustParseConstraint("~1.2 || ~1.4"), Format: FormatJSON}
+	if err := Match(repo, opts, out); err != nil {
+		t.Fatalf("TestMatch: unexpected error: %s", err.Error())
+	}
+	doc := &versionDocument{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil {
+		t.Fatalf("TestMatch: invalid JSON: %s", err.Error())
+	}
+	if len(doc.Versions) != 2 || doc.Versions[0].Tag != "v1.4.2" || doc.Versions[1].Tag != "v1.2.0" {
+		t.Errorf("TestMatch: unexpected versions:\n%s", out.String())
+	}
+
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editorHelp is appended to the message opened in the editor
+const editorHelp = `
+# Write the annotation of tag %s. These help lines
+# are removed, an empty message aborts the version update.
+`
+
+// ReadMessage reads a tag message either given inline or stored in a file
+func ReadMessage(text, file string) (string, error) {
+
+	if text != "" && file != "" {
+		return "", fmt.Errorf("cannot use both a message and a message file")
+	}
+
+	if file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("could not read message file: %s", err.Error())
+		}
+		text = string(content)
+	}
+
+	return strings.TrimSpace(text), nil
+}
+
+// tagMessage assembles the annotation of a new version's tag. The default
+// message is "Version vX.Y.Z" ("Version vX.Y.Z of component" for monorepo
+// components), release notes are appended if given
+func tagMessage(version *Version, message string, notes *Changelog) string {
+
+	if message == "" {
+		message = fmt.Sprintf("Version %s", version.String())
+		if version.Component != "" {
+			message += " of " + version.Component
+		}
+	}
+
+	if notes != nil {
+		if text := notes.Text(); text != "" {
+			message += "\n\n" + text
+		}
+	}
+
+	return strings.TrimSpace(message)
+}
+
+// editMessage opens a message in the user's editor ($VISUAL, $EDITOR or
+// vi) and returns the edited message without the editor help
+func editMessage(message, tag string) (string, error) {
+
+	file, err := ioutil.TempFile("", "version-tag-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("could not create message file: %s", err.Error())
+	}
+	defer os.Remove(file.Name())
+
+	_, err = fmt.Fprintf(file, "%s\n"+editorHelp, message, tag)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("could not write message file: %s", err.Error())
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor may come with arguments, e.g. "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, file.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor '%s' failed: %s", editor, err.Error())
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("could not read message file: %s", err.Error())
+	}
+
+	return stripHelp(string(content), tag), nil
+}
+
+// stripHelp removes the lines of the editor help of a tag and surrounding
+// whitespace. Other lines starting with '#', e.g. issue references, are
+// kept, just like git keeps them in tag messages
+func stripHelp(message, tag string) string {
+
+	help := map[string]bool{}
+	for _, line := range strings.Split(fmt.Sprintf(editorHelp, tag), "\n") {
+		if line != "" {
+			help[line] = true
+		}
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); !help[line] {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/vaitekunas/version/semver"
+)
+
+func TestReadMessage(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "message.txt")
+	ioutil.WriteFile(file, []byte("\nRelease from file\n\n"), 0644)
+
+	tests := []struct {
+		text, file string
+		expected   string
+		err        bool
+	}{
+		{"", "", "", false},
+		{"  Inline release \n", "", "Inline release", false},
+		{"", file, "Release from file", false},
+		{"Inline", file, "", true},
+		{"", file + ".missing", "", true},
+	}
+
+	for i, test := range tests {
+		message, err := ReadMessage(test.text, test.file)
+		if (err != nil) != test.err || message != test.expected {
+			t.Errorf("TestReadMessage: test %d failed: got '%s' (%v)", i+1, message, err)
+		}
+	}
+
+}
+
+func TestTagMessage(t *testing.T) {
+
+	version := &Version{Version: *semver.MustParse("v1.5.0")}
+
+	notes := &Changelog{}
+	notes.add(&Commit{Hash: "46a2962f4433c7a1f6e7d0ffbc72c9c63ba06c34", Message: "feat(cli): add --auto"})
+	notes.add(&Commit{Hash: "1788554f4433c7a1f6e7d0ffbc72c9c63ba06c34", Message: "fix: handle empty repositories"})
+
+	tests := []struct {
+		message  string
+		notes    *Changelog
+		expected string
+	}{
+		{"", nil, "Version v1.5.0"},
+		{"Codename versailles", nil, "Codename versailles"},
+		{"", &Changelog{}, "Version v1.5.0"},
+		{"", notes, "Version v1.5.0\n\nFeatures:\n- cli: add --auto (46a2962)\n\nFixes:\n- handle empty repositories (1788554)"},
+	}
+
+	for i, test := range tests {
+		if message := tagMessage(version, test.message, test.notes); message != test.expected {
+			t.Errorf("TestTagMessage: test %d failed: got %q", i+1, message)
+		}
+	}
+
+}
+
+func TestStripHelp(t *testing.T) {
+
+	message := "\nVersion v1.5.0  \n\n - fix\n#123 fixed\n" + fmt.Sprintf(editorHelp, "v1.5.0")
+	if stripped := stripHelp(message, "v1.5.0"); stripped != "Version v1.5.0\n\n - fix\n#123 fixed" {
+		t.Errorf("TestStripHelp: got %q", stripped)
+	}
+
+}
+
+func TestIncreaseMessage(t *testing.T) {
+
+	color.NoColor = true
+
+	setup := func(name string) *fakeRepository {
+		repo := newFakeRepository("/message/" + name)
+		repo.commit("feat: a")
+		repo.tag("v1.4.2")
+		repo.commit("fix: b")
+		repo.commit("docs: c")
+		return repo
+	}
+
+	tests := []struct {
+		opts     *IncreaseOptions
+		editor   string
+		expected string
+		err      error
+	}{
+		{&IncreaseOptions{Yes: true}, "", "Version v1.4.3", nil},
+		{&IncreaseOptions{Yes: true, Message: "Hotfix release"}, "", "Hotfix release", nil},
+		{&IncreaseOptions{Yes: true, Notes: true}, "", "Version v1.4.3\n\nFixes:\n- b (", nil},
+		{&IncreaseOptions{Yes: true, Edit: true}, "sed -i s/Version/Edited/", "Edited v1.4.3", nil},
+		{&IncreaseOptions{Yes: true, Edit: true}, "sed -i /Version/d", "", ErrAborted},
+		{&IncreaseOptions{Yes: true, Edit: true}, "false", "", nil},
+	}
+
+	for i, test := range tests {
+		repo := setup(string(rune('a' + i)))
+		t.Setenv("VISUAL", test.editor)
+
+		out := &bytes.Buffer{}
+		err := Increase(repo, test.opts, strings.NewReader(""), out)
+		if test.expected == "" {
+			if err == nil || test.err != nil && err != test.err || len(repo.tags) != 1 {
+				t.Errorf("TestIncreaseMessage: test %d failed: expected no tag, got %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestIncreaseMessage: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		if message := repo.messages["v1.4.3"]; !strings.HasPrefix(message, test.expected) {
+			t.Errorf("TestIncreaseMessage: test %d failed: unexpected tag message %q", i+1, message)
+		}
+		if !strings.Contains(out.String(), "Tag message:\n") {
+			t.Errorf("TestIncreaseMessage: test %d failed: tag message is not shown", i+1)
+		}
+	}
+
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// nextDocument is the JSON document of the next version
+type nextDocument struct {
+	Schema     int    `json:"schema"`
+	Repository string `json:"repository"`
+	Component  string `json:"component,omitempty"`
+	Current    string `json:"current"` // current version, empty if there is none
+	Version    string `json:"version"` // version after the increase
+	Tag        string `json:"tag"`     // name of the tag an increase would create
+	Level      string `json:"level"`   // applied tick: major, minor, patch or none
+
+	// Commits that drove an automatic increase
+	Commits []*ConventionalCommit `json:"commits,omitempty"`
+}
+
+// Next prints the version an increase with the same options would tag,
+// e.g. v1.3.0. Only the version is computed: neither the commit to be
+// tagged nor the module path are checked. opts.Format is either empty
+// (the version only) or json
+func Next(repo Repository, opts *IncreaseOptions, w io.Writer) error {
+
+	if opts.Format != "" && opts.Format != FormatJSON {
+		return fmt.Errorf("unknown format '%s': choose json", opts.Format)
+	}
+
+	inc, err := nextVersion(repo, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == "" {
+		_, err := fmt.Fprintln(w, inc.version.String())
+		return err
+	}
+
+	doc := &nextDocument{
+		Schema:     SchemaVersion,
+		Repository: repo.Path(),
+		Component:  opts.Component,
+		Version:    inc.version.String(),
+		Tag:        ComponentTag(opts.Component, inc.version, inc.scheme),
+		Level:      inc.level.String(),
+		Commits:    inc.drivers,
+	}
+	if len(inc.versions.versions) >= 1 {
+		doc.Current = inc.current.String()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(doc)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNext(t *testing.T) {
+
+	tests := []struct {
+		setup    func(repo *fakeRepository)
+		opts     *IncreaseOptions
+		expected string
+		err      string
+	}{
+		{func(repo *fakeRepository) { repo.commit("initial") }, &IncreaseOptions{}, "v0.0.1\n", ""},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("b") }, &IncreaseOptions{Minor: true}, "v0.15.0\n", ""},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("feat: b") }, &IncreaseOptions{Auto: true}, "v0.15.0\n", ""},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1") }, &IncreaseOptions{Major: true, Special: "rc.1"}, "v1.0.0-rc.1\n", ""},
+
+		// Tagged commits do not prevent computing the next version
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.2.3", "api/v0.1.0") }, &IncreaseOptions{Component: "api"}, "v0.1.1\n", ""},
+
+		// Errors of the computation are reported
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Major: true, Minor: true}, "", "cannot increase more than one level"},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.0.0"); repo.commit("docs: b") }, &IncreaseOptions{Auto: true}, "", "could not derive increase"},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Format: FormatYAML}, "", "unknown format"},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Minor: true, Special: "rc_1!"}, "", "invalid pre-release or build metadata"},
+	}
+
+	for i, test := range tests {
+		repo := newFakeRepository("/next")
+		test.setup(repo)
+		tags := len(repo.tags)
+
+		out := &bytes.Buffer{}
+		err := Next(repo, test.opts, out)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("TestNext: test %d failed: expected error '%s', got %v", i+1, test.err, err)
+			}
+			if _, ok := err.(*UsageError); ok != strings.HasPrefix(test.err, "invalid") {
+				t.Errorf("TestNext: test %d failed: unexpected usage error state: %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestNext: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		if out.String() != test.expected {
+			t.Errorf("TestNext: test %d failed: got '%s', expected '%s'", i+1, out.String(), test.expected)
+		}
+		if len(repo.tags) != tags {
+			t.Errorf("TestNext: test %d failed: repository was tagged", i+1)
+		}
+	}
+
+}
+
+func TestNextJSON(t *testing.T) {
+
+	repo := newFakeRepository("/next/json")
+	repo.commit("a")
+	repo.tag("v0.14.1", "api@0.2.0")
+	repo.commit("feat(api): b")
+
+	tests := []struct {
+		opts     *IncreaseOptions
+		expected nextDocument
+	}{
+		{&IncreaseOptions{Auto: true, Format: FormatJSON}, nextDocument{Current: "v0.14.1", Version: "v0.15.0", Tag: "v0.15.0", Level: "minor"}},
+		{&IncreaseOptions{Major: true, Component: "api", TagScheme: TagSchemeAt, Format: FormatJSON}, nextDocument{Component: "api", Current: "v0.2.0", Version: "v1.0.0", Tag: "api@1.0.0", Level: "major"}},
+		{&IncreaseOptions{Component: "web", Format: FormatJSON}, nextDocument{Component: "web", Version: "v0.0.1", Tag: "web/v0.0.1", Level: "patch"}},
+	}
+
+	for i, test := range tests {
+		out := &bytes.Buffer{}
+		if err := Next(repo, test.opts, out); err != nil {
+			t.Fatalf("TestNextJSON: test %d failed: unexpected error: %s", i+1, err.Error())
+		}
+		doc := &nextDocument{}
+		if err := json.Unmarshal(out.Bytes(), doc); err != nil {
+			t.Fatalf("TestNextJSON: test %d failed: invalid JSON: %s", i+1, err.Error())
+		}
+		test.expected.Schema, test.expected.Repository = SchemaVersion, repo.path
+		if test.opts.Auto && len(doc.Commits) != 1 {
+			t.Errorf("TestNextJSON: test %d failed: expected the driving commit:\n%s", i+1, out.String())
+		}
+		doc.Commits = nil
+		if got, expected := fmt.Sprintf("%+v", *doc), fmt.Sprintf("%+v", test.expected); got != expected {
+			t.Errorf("TestNextJSON: test %d failed: got %s, expected %s", i+1, got, expected)
+		}
+	}
+
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ListRemote lists the versions of a remote, given by name or URL, without
+// fetching its tags. Commit dates are only known for commits that exist
+// in repo, e.g. because they were fetched before
+func ListRemote(repo Repository, remote string, opts *ListOptions, w io.Writer) error {
+
+	tags, err := repo.RemoteTags(remote)
+	if err != nil {
+		return fmt.Errorf("could not list tags of '%s': %s", remote, err.Error())
+	}
+
+	for _, tag := range tags {
+		if commit, err := repo.Commit(tag.Commit); err == nil {
+			tag.Date = commit.Date
+		}
+	}
+
+	repos := []string{remote}
+	repoVersions := map[string]*Versions{remote: newVersions(tags, opts.TieBreak)}
+
+	if opts.Template != nil {
+		return printVersionTemplate(w, opts.Template, repos, repoVersions, !opts.All)
+	}
+
+	// Ref advertisements carry no tag metadata
+	return printVersions(w, opts.Format, repos, repoVersions, !opts.All, false)
+}
+
+// Version tag states of a repository compared with a remote
+const (
+	StatusSynced     = "synced"      // same commit locally and on the remote
+	StatusLocalOnly  = "local-only"  // never pushed
+	StatusRemoteOnly = "remote-only" // not fetched
+	StatusDiverged   = "diverged"    // same name, different commits
+)
+
+// ErrDiverged is returned when local and remote version tags differ
+var ErrDiverged = errors.New("local and remote versions differ")
+
+// StatusOptions holds the parameters of a version status
+type StatusOptions struct {
+	Remote string // remote name or URL
+	All    bool   // also list synced versions
+	Format string // table or json
+}
+
+// TagStatus compares a version tag of a repository with a remote
+type TagStatus struct {
+	Tag     string `json:"tag"`
+	Version string `json:"version"`          // normalized version
+	Local   string `json:"local,omitempty"`  // local commit, empty if not fetched
+	Remote  string `json:"remote,omitempty"` // remote commit, empty if not pushed
+	State   string `json:"state"`            // synced, local-only, remote-only or diverged
+}
+
+// statusDocument is the JSON document of a version status
+type statusDocument struct {
+	Schema     int          `json:"schema"`
+	Repository string       `json:"repository"`
+	Remote     string       `json:"remote"`
+	Tags       []*TagStatus `json:"tags"`
+}
+
+// CompareRemote compares the version tags of a repository with those of a
+// remote, ordered from the highest to the lowest version
+func CompareRemote(repo Repository, remote string) ([]*TagStatus, error) {
+
+	local, err := GetVersions(repo, TieBreakNone)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := repo.RemoteTags(remote)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags of '%s': %s", remote, err.Error())
+	}
+	upstream := newVersions(tags, TieBreakNone)
+
+	// Merge both sides by tag name
+	statuses := map[string]*TagStatus{}
+	all := []*Tag{}
+	for _, version := range local.versions {
+		statuses[version.Tag] = &TagStatus{Tag: version.Tag, Version: version.String(), Local: version.Commit}
+		all = append(all, &Tag{Name: version.Tag})
+	}
+	for _, version := range upstream.versions {
+		status, ok := statuses[version.Tag]
+		if !ok {
+			status = &TagStatus{Tag: version.Tag, Version: version.String()}
+			statuses[version.Tag] = status
+			all = append(all, &Tag{Name: version.Tag})
+		}
+		status.Remote = version.Commit
+	}
+
+	result := []*TagStatus{}
+	for _, version := range newVersions(all, TieBreakBuild).versions {
+		status := statuses[version.Tag]
+		switch {
+		case status.Remote == "":
+			status.State = StatusLocalOnly
+		case status.Local == "":
+			status.State = StatusRemoteOnly
+		case status.Local != status.Remote:
+			status.State = StatusDiverged
+		default:
+			status.State = StatusSynced
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// Status prints the differences between the version tags of a repository
+// and a remote. Returns ErrDiverged if there are any
+func Status(repo Repository, opts *StatusOptions, w io.Writer) error {
+
+	switch opts.Format {
+	case FormatTable, FormatJSON, "":
+	default:
+		return fmt.Errorf("unknown format '%s': choose table or json", opts.Format)
+	}
+
+	statuses, err := CompareRemote(repo, opts.Remote)
+	if err != nil {
+		return err
+	}
+
+	diverged := false
+	listed := []*TagStatus{}
+	for _, status := range statuses {
+		if status.State != StatusSynced {
+			diverged = true
+		}
+		if opts.All || status.State != StatusSynced {
+			listed = append(listed, status)
+		}
+	}
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		doc := &statusDocument{Schema: SchemaVersion, Repository: repo.Path(), Remote: opts.Remote, Tags: listed}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	} else {
+		printStatusTable(w, repo.Path(), opts.Remote, listed)
+	}
+
+	if diverged {
+		return ErrDiverged
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestListRemote(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/remote/list")
+	repo.commit("a")
+	repo.tag("v0.1.0")
+	repo.PushTag("origin", "v0.1.0")
+	repo.remotes["origin"]["v0.2.0"] = "0000000000000000000000000000000000000000"
+	repo.remotes["origin"]["docs"] = repo.head
+
+	// Highest version
+	out := &bytes.Buffer{}
+	if err := ListRemote(repo, "origin", &ListOptions{Format: FormatPlain}, out); err != nil {
+		t.Fatalf("TestListRemote: unexpected error: %s", err.Error())
+	}
+	if expected := "origin  -  0000000  v0.2.0\n"; out.String() != expected {
+		t.Errorf("TestListRemote: expected '%s', got '%s'", expected, out.String())
+	}
+
+	// Dates are only known for local commits
+	out.Reset()
+	if err := ListRemote(repo, "fake://origin", &ListOptions{All: true, Format: FormatJSON}, out); err != nil {
+		t.Fatalf("TestListRemote: unexpected error: %s", err.Error())
+	}
+	doc := &versionDocument{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil {
+		t.Fatalf("TestListRemote: invalid JSON: %s", err.Error())
+	}
+	if len(doc.Versions) != 2 || doc.Versions[0].Date != nil || doc.Versions[1].Date == nil || !doc.Versions[1].Date.Equal(repo.date) {
+		t.Errorf("TestListRemote: unexpected versions:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), `"date": "0001`) {
+		t.Errorf("TestListRemote: unknown date was rendered:\n%s", out.String())
+	}
+
+	// Unknown remotes
+	if err := ListRemote(repo, "fork", &ListOptions{}, out); err == nil || !strings.Contains(err.Error(), "could not list tags of 'fork'") {
+		t.Errorf("TestListRemote: expected an error, got %v", err)
+	}
+
+}
+
+func TestStatus(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/remote/status")
+	repo.commit("a")
+	repo.tag("v0.1.0", "docs")
+	repo.PushTag("origin", "v0.1.0")
+	repo.PushTag("origin", "docs")
+
+	// In sync
+	out := &bytes.Buffer{}
+	if err := Status(repo, &StatusOptions{Remote: "origin"}, out); err != nil {
+		t.Errorf("TestStatus: unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "in sync with 'origin'") {
+		t.Errorf("TestStatus: unexpected output:\n%s", out.String())
+	}
+
+	// Never pushed, not fetched and diverged tags
+	repo.commit("b")
+	repo.tag("v0.2.0", "v0.3.0")
+	repo.PushTag("origin", "v0.3.0")
+	repo.DeleteTag("v0.3.0")
+	repo.commit("c")
+	repo.tag("v0.3.0")
+	repo.remotes["origin"]["v0.4.0"] = "0000000000000000000000000000000000000000"
+
+	statuses, err := CompareRemote(repo, "origin")
+	if err != nil {
+		t.Fatalf("TestStatus: unexpected error: %s", err.Error())
+	}
+	expected := []string{"v0.4.0 remote-only", "v0.3.0 diverged", "v0.2.0 local-only", "v0.1.0 synced"}
+	if len(statuses) != len(expected) {
+		t.Fatalf("TestStatus: expected %d versions, got %d", len(expected), len(statuses))
+	}
+	for i, status := range statuses {
+		if got := status.Tag + " " + status.State; got != expected[i] {
+			t.Errorf("TestStatus: version %d: expected '%s', got '%s'", i+1, expected[i], got)
+		}
+	}
+
+	// Synced versions are only listed with All
+	out.Reset()
+	if err := Status(repo, &StatusOptions{Remote: "origin", Format: FormatJSON}, out); err != ErrDiverged {
+		t.Errorf("TestStatus: expected ErrDiverged, got %v", err)
+	}
+	doc := &statusDocument{}
+	if err := json.Unmarshal(out.Bytes(), doc); err != nil || len(doc.Tags) != 3 || doc.Remote != "origin" || doc.Tags[0].Local != "" {
+		t.Errorf("TestStatus: unexpected JSON (%v):\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err := Status(repo, &StatusOptions{Remote: "origin", All: true}, out); err != ErrDiverged {
+		t.Errorf("TestStatus: expected ErrDiverged, got %v", err)
+	}
+	for _, line := range expected {
+		fields := strings.Fields(line)
+		if !strings.Contains(out.String(), fields[0]) || !strings.Contains(out.String(), fields[1]) {
+			t.Errorf("TestStatus: '%s' is missing:\n%s", line, out.String())
+		}
+	}
+
+	// Errors
+	if err := Status(repo, &StatusOptions{Remote: "fork"}, out); err == nil || err == ErrDiverged {
+		t.Errorf("TestStatus: expected an error for an unknown remote, got %v", err)
+	}
+	if err := Status(repo, &StatusOptions{Remote: "origin", Format: FormatYAML}, out); err == nil || err == ErrDiverged {
+		t.Errorf("TestStatus: expected an error for an unsupported format, got %v", err)
+	}
+
+}
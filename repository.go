@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	BackendAuto  = "auto"   // exec if git is installed, go-git otherwise
+	BackendExec  = "exec"   // shells out to the git binary
+	BackendGoGit = "go-git" // embedded pure-Go git implementation
+)
+
+// Repository abstracts the git operations needed to manage versions
+type Repository interface {
+
+	// Path returns the path of the repository
+	Path() string
+
+	// Tags lists all the tags of the repository
+	Tags() ([]*Tag, error)
+
+	// Head returns the currently checked out commit
+	Head() (*Commit, error)
+
+	// Commit returns the commit a revision (hash, tag, branch) resolves to
+	Commit(rev string) (*Commit, error)
+
+	// Commits lists the commits reachable from revision to, but not from
+	// revision from, newest first. An empty from lists the whole history.
+	// Given paths (relative to the repository root) limit the list to the
+	// commits changing files in them
+	Commits(from, to string, paths ...string) ([]*Commit, error)
+
+	// CreateTag creates an annotated tag on the commit a revision resolves
+	// to. The tag is signed unless sign is nil
+	CreateTag(name, message, rev string, sign *SignOptions) error
+
+	// CommitFiles commits the changes of files (absolute paths) on top of
+	// HEAD and returns the new commit
+	CommitFiles(message string, paths []string) (*Commit, error)
+
+	// Dirty reports whether tracked files have uncommitted changes
+	Dirty() (bool, error)
+
+	// DeleteTag deletes a local tag
+	DeleteTag(name string) error
+
+	// PushTag pushes a single tag to a remote
+	PushTag(remote, name string) error
+
+	// RemoteTags lists the tags advertised by a remote, given by name or
+	// URL, without fetching them. Only the tagged commits are known
+	RemoteTags(remote string) ([]*Tag, error)
+
+	// VerifyTag checks the signature of a tag, see the Signature* states
+	VerifyTag(name string) (string, error)
+
+	// Config returns the value of a git configuration key, e.g.
+	// "version.sign", or an empty string if it is not set
+	Config(key string) (string, error)
+
+	// Branch returns the name of the active branch
+	Branch() (string, error)
+}
+
+// Commit holds the metadata of a single commit
+type Commit struct {
+	Hash       string
+	Date       time.Time // author date
+	CommitDate time.Time // committer date, differs from the author date for rebased commits
+	Author     string
+	Message    string // subject line
+	Body       string // rest of the commit message
+}
+
+// Tag holds a tag and the commit it points to
+type Tag struct {
+	Name      string
+	Commit    string
+	Date      time.Time // author date of the commit
+	TagDate   time.Time // tagger date, the committer date for lightweight tags
+	Annotated bool
+	Tagger    string // "Name <email>" of annotated tags
+	Message   string // annotation message of annotated tags
+}
+
+// backends maps backend names to repository constructors
+var backends = map[string]func(path string) (Repository, error){
+	BackendExec:  NewExecRepository,
+	BackendGoGit: NewGoGitRepository,
+}
+
+// OpenRepository opens the repository at path using the selected backend
+func OpenRepository(path, backend string) (Repository, error) {
+
+	backend = strings.ToLower(backend)
+	if backend == BackendAuto {
+		backend = BackendGoGit
+		if _, err := exec.LookPath("git"); err == nil {
+			backend = BackendExec
+		}
+	}
+
+	open, ok := backends[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend '%s': choose auto, exec or go-git", backend)
+	}
+
+	return open(path)
+}
+
+// inPaths reports whether a file (slash-separated, relative to the
+// repository root) is one of paths or lies in one of them
+func inPaths(file string, paths []string) bool {
+	for _, path := range paths {
+		path = strings.Trim(filepath.ToSlash(path), "/")
+		if path == "" || file == path || strings.HasPrefix(file, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteTags converts advertised refs (name and hash) into tags. Annotated
+// tags are advertised twice, the peeled ref "refs/tags/name^{}" points to
+// the tagged commit instead of the tag object
+func remoteTags(refs [][2]string) []*Tag {
+
+	tags := []*Tag{}
+	byName := map[string]*Tag{}
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref[0], "refs/tags/") {
+			continue
+		}
+		name := strings.TrimPrefix(ref[0], "refs/tags/")
+		peeled := strings.HasSuffix(name, "^{}")
+		name = strings.TrimSuffix(name, "^{}")
+
+		tag, ok := byName[name]
+		if !ok {
+			tag = &Tag{Name: name}
+			byName[name] = tag
+			tags = append(tags, tag)
+		}
+		if peeled || tag.Commit == "" {
+			tag.Commit = ref[1]
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// execRepository implements Repository by shelling out to the git binary
+type execRepository struct {
+	path string
+}
+
+// NewExecRepository creates a git binary backed repository
+func NewExecRepository(path string) (Repository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found: use the go-git backend instead")
+	}
+	return &execRepository{path: path}, nil
+}
+
+// git prepares a git command running in the repository
+func (r *execRepository) git(args ...string) *exec.Cmd {
+	return exec.Command("git", append([]string{"-C", r.path}, args...)...)
+}
+
+// Path implements Repository.Path
+func (r *execRepository) Path() string {
+	return r.path
+}
+
+// tagRefFormat lists a tag ref with the object it points to, the peeled
+// object and the tagger and message of annotated tags. The raw message
+// includes the signature of signed tags, which is listed separately. Fields
+// are separated by NUL, records end in NUL and a newline, since messages
+// can span several lines
+const tagRefFormat = "%(refname)%00%(objecttype)%00%(objectname)%00%(authordate:unix)%00%(*objecttype)%00%(*objectname)%00%(*authordate:unix)%00%(creatordate:unix)%00%(taggername)%00%(taggeremail)%00%(contents)%00%(contents:signature)%00"
+
+// Tags implements Repository.Tags. Tags are enumerated from the tag refs,
+// i.e. every tag is listed, even if a commit has several of them
+func (r *execRepository) Tags() ([]*Tag, error) {
+
+	out, err := r.git("for-each-ref", "--format="+tagRefFormat, "refs/tags").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags: %s", err.Error())
+	}
+
+	return parseTagRefs(string(out)), nil
+}
+
+// parseTagRefs parses the output of for-each-ref with tagRefFormat.
+// Annotated tags are resolved to the tagged commit, the tag date being the
+// tagger's date. Tags of other objects (trees, blobs, tags) are skipped
+func parseTagRefs(out string) []*Tag {
+
+	tags := []*Tag{}
+	for _, record := range strings.Split(out, "\x00\n") {
+
+		parts := strings.Split(record, "\x00")
+		if len(parts) != 12 || !strings.HasPrefix(parts[0], "refs/tags/") {
+			continue
+		}
+
+		// Lightweight tags point to the commit directly
+		objectType, hash, date := parts[1], parts[2], parts[3]
+		annotated := objectType == "tag"
+		if annotated {
+			objectType, hash, date = parts[4], parts[5], parts[6]
+		}
+		if objectType != "commit" {
+			continue
+		}
+
+		commitDate, err := parseTimestamp(date)
+		if err != nil {
+			continue
+		}
+		tagDate, err := parseTimestamp(parts[7])
+		if err != nil {
+			tagDate = commitDate
+		}
+
+		tag := &Tag{
+			Name:      strings.TrimPrefix(parts[0], "refs/tags/"),
+			Commit:    hash,
+			Date:      commitDate,
+			TagDate:   tagDate,
+			Annotated: annotated,
+		}
+
+		// The contents of lightweight tags are the commit message
+		if annotated {
+			tag.Tagger = strings.TrimSpace(parts[8] + " " + parts[9])
+			tag.Message = strings.TrimSpace(strings.TrimSuffix(parts[10], parts[11]))
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// Head implements Repository.Head
+func (r *execRepository) Head() (*Commit, error) {
+	return r.Commit("HEAD")
+}
+
+// Commit implements Repository.Commit
+func (r *execRepository) Commit(rev string) (*Commit, error) {
+
+	commits, err := r.log("-1", rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit '%s': %s", rev, err.Error())
+	}
+	if len(commits) != 1 {
+		return nil, fmt.Errorf("invalid git output")
+	}
+
+	return commits[0], nil
+}
+
+// Commits implements Repository.Commits
+func (r *execRepository) Commits(from, to string, paths ...string) ([]*Commit, error) {
+
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+
+	commits, err := r.log(append([]string{rev, "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits: %s", err.Error())
+	}
+
+	return commits, nil
+}
+
+// log runs git log and parses the listed commits. Fields are separated by
+// NUL and commits by the record separator, since messages span lines
+func (r *execRepository) log(args ...string) ([]*Commit, error) {
+
+	cmd := r.git(append([]string{"log", "--pretty=format:%H%x00%at%x00%ct%x00%an%x00%s%x00%b%x1e"}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*Commit{}
+	for _, record := range strings.Split(string(out), "\x1e") {
+
+		parts := strings.Split(strings.TrimSpace(record), "\x00")
+		if len(parts) != 6 {
+			continue
+		}
+
+		date, err := parseTimestamp(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		commitDate, err := parseTimestamp(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		commits = append(commits, &Commit{
+			Hash:       parts[0],
+			Date:       date,
+			CommitDate: commitDate,
+			Author:     parts[3],
+			Message:    parts[4],
+			Body:       strings.TrimSpace(parts[5]),
+		})
+	}
+
+	return commits, nil
+}
+
+// CreateTag implements Repository.CreateTag
+func (r *execRepository) CreateTag(name, message, rev string, sign *SignOptions) error {
+
+	// Lines starting with '#' are kept, just like with go-git
+	args := []string{"tag", "-a", "--cleanup=whitespace", name, "-m", message, rev}
+	if sign != nil {
+		args[1] = "-s"
+		if sign.Key != "" {
+			args[1] = "--local-user=" + sign.Key
+		}
+		if sign.Format != "" {
+			args = append([]string{"-c", "gpg.format=" + sign.Format}, args...)
+		}
+	}
+
+	if out, err := r.git(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// CommitFiles implements Repository.CommitFiles
+func (r *execRepository) CommitFiles(message string, paths []string) (*Commit, error) {
+
+	if out, err := r.git(append([]string{"add", "--"}, paths...)...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+	if out, err := r.git(append([]string{"commit", "-q", "-m", message, "--"}, paths...)...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+
+	return r.Head()
+}
+
+// Dirty implements Repository.Dirty
+func (r *execRepository) Dirty() (bool, error) {
+
+	out, err := r.git("status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, fmt.Errorf("could not get status: %s", err.Error())
+	}
+
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// DeleteTag implements Repository.DeleteTag
+func (r *execRepository) DeleteTag(name string) error {
+
+	if out, err := r.git("tag", "-d", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// PushTag implements Repository.PushTag
+func (r *execRepository) PushTag(remote, name string) error {
+
+	ref := "refs/tags/" + name
+	if out, err := r.git("push", "--porcelain", remote, ref+":"+ref).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// RemoteTags implements Repository.RemoteTags
+func (r *execRepository) RemoteTags(remote string) ([]*Tag, error) {
+
+	out, err := r.git("ls-remote", "--tags", remote).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return nil, fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(exitErr.Stderr)))
+	} else if err != nil {
+		return nil, err
+	}
+
+	refs := [][2]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "\t")
+		if len(parts) == 2 {
+			refs = append(refs, [2]string{parts[1], parts[0]})
+		}
+	}
+
+	return remoteTags(refs), nil
+}
+
+// VerifyTag implements Repository.VerifyTag
+func (r *execRepository) VerifyTag(name string) (string, error) {
+
+	// Lightweight tags and tags without a signature
+	out, err := r.git("for-each-ref", "--format=%(contents:signature)", "refs/tags/"+name).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not get tag '%s': %s", name, err.Error())
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return SignatureUnsigned, nil
+	}
+
+	out, err = r.git("tag", "-v", name).CombinedOutput()
+	if err == nil {
+		return SignatureGood, nil
+	}
+
+	// gpg reports "BAD signature", ssh-keygen "incorrect signature".
+	// Anything else, e.g. a missing key, means the signature is not checked
+	if text := string(out); strings.Contains(text, "BAD signature") || strings.Contains(text, "incorrect signature") {
+		return SignatureBad, nil
+	}
+
+	return SignatureUnknown, nil
+}
+
+// Config implements Repository.Config
+func (r *execRepository) Config(key string) (string, error) {
+
+	out, err := r.git("config", "--get", key).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not read '%s': %s", key, err.Error())
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Branch implements Repository.Branch
+func (r *execRepository) Branch() (string, error) {
+
+	// Get last log
+	cmd := r.git("branch", "--all")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not get branches: %s", err.Error())
+	}
+
+	// Find active branch
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.Trim(line, `"`)
+		if strings.HasPrefix(line, "*") {
+			if len(line) < 3 {
+				return "", fmt.Errorf("invalid branch name")
+			}
+			return line[2:], nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine active branch")
+}
+
+// parseTimestamp parses a UNIX timestamp
+func parseTimestamp(s string) (time.Time, error) {
+	tint, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse string to UNIX timestamp")
+	}
+	return time.Unix(tint, 0), nil
+}
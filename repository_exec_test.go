@@ -0,0 +1,455 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newGitRepository initializes a git repository in a temporary directory.
+// Tests using it are skipped when git is not installed
+func newGitRepository(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "master")
+	runGit(t, dir, "config", "user.name", "Tester")
+	runGit(t, dir, "config", "user.email", "tester@example.com")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	runGit(t, dir, "config", "tag.gpgsign", "false")
+
+	return dir
+}
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2017-09-07T12:00:00Z", "GIT_COMMITTER_DATE=2017-09-07T12:00:00Z")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %s: %s", strings.Join(args, " "), err.Error(), out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func TestRepositoryBackends(t *testing.T) {
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("TestRepositoryBackends: %s", err.Error())
+	}
+
+	// Repositories with different version histories
+	dirs := []string{}
+	for i := 0; i < 4; i++ {
+		dir := newGitRepository(t)
+		for j := 0; j <= i; j++ {
+			runGit(t, dir, "commit", "-q", "--allow-empty", "-m", fmt.Sprintf("commit %d", j), "-m", fmt.Sprintf("body %d\n\nBREAKING CHANGE: none", j))
+			runGit(t, dir, "tag", fmt.Sprintf("v%d.%d.0", i, j))
+		}
+		runGit(t, dir, "tag", "-a", "-m", "release candidate", fmt.Sprintf("v%d.%d.0-rc.1", i, i+1))
+		dirs = append(dirs, dir)
+	}
+
+	// Scan all repositories concurrently with both backends
+	var wg sync.WaitGroup
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		for i, dir := range dirs {
+			wg.Add(1)
+			go func(backend string, i int, dir string) {
+				defer wg.Done()
+
+				repo, err := OpenRepository(dir, backend)
+				if err != nil {
+					t.Errorf("TestRepositoryBackends: %s: %s", backend, err.Error())
+					return
+				}
+
+				versions, err := GetVersions(repo, TieBreakNone)
+				if err != nil {
+					t.Errorf("TestRepositoryBackends: %s: %s", backend, err.Error())
+					return
+				}
+				if len(versions.versions) != i+2 {
+					t.Errorf("TestRepositoryBackends: %s: expected %d versions in %s, got %d", backend, i+2, dir, len(versions.versions))
+					return
+				}
+				if highest := versions.versions[0].String(); highest != fmt.Sprintf("v%d.%d.0-rc.1", i, i+1) {
+					t.Errorf("TestRepositoryBackends: %s: unexpected highest version %s in %s", backend, highest, dir)
+				}
+
+				head, err := repo.Head()
+				if err != nil {
+					t.Errorf("TestRepositoryBackends: %s: %s", backend, err.Error())
+					return
+				}
+				if head.Message != fmt.Sprintf("commit %d", i) || head.Body != fmt.Sprintf("body %d\n\nBREAKING CHANGE: none", i) || head.Author != "Tester" || head.Hash != versions.versions[0].Commit {
+					t.Errorf("TestRepositoryBackends: %s: unexpected HEAD %+v", backend, head)
+				}
+
+				if branch, err := repo.Branch(); err != nil || branch != "master" {
+					t.Errorf("TestRepositoryBackends: %s: unexpected branch '%s' (%v)", backend, branch, err)
+				}
+
+				// Commits since the previous version
+				if i > 0 {
+					commits, err := repo.Commits(versions.versions[2].Commit, "HEAD")
+					if err != nil || len(commits) != 1 || commits[0].Hash != head.Hash {
+						t.Errorf("TestRepositoryBackends: %s: unexpected commits since %s: %v (%v)", backend, versions.versions[2], commits, err)
+					}
+				}
+				if all, err := repo.Commits("", "HEAD"); err != nil || len(all) != i+1 || all[i].Message != "commit 0" {
+					t.Errorf("TestRepositoryBackends: %s: unexpected history: %v (%v)", backend, all, err)
+				}
+			}(backend, i, dir)
+		}
+	}
+	wg.Wait()
+
+	// The working directory is never changed
+	if after, _ := os.Getwd(); after != wd {
+		t.Errorf("TestRepositoryBackends: working directory changed from %s to %s", wd, after)
+	}
+
+	// Tags are created in the right repository
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dirs[0], backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryBackends: %s: %s", backend, err.Error())
+		}
+		name := fmt.Sprintf("v5.0.0-%s", backend)
+		if err := repo.CreateTag(name, "Version "+name+"\n\n# kept", "HEAD", nil); err != nil {
+			t.Errorf("TestRepositoryBackends: %s: could not create tag: %s", backend, err.Error())
+			continue
+		}
+		if out := runGit(t, dirs[0], "tag", "-l", name, "-n1"); !strings.Contains(out, "Version "+name) {
+			t.Errorf("TestRepositoryBackends: %s: tag was not created: '%s'", backend, out)
+		}
+		if out := runGit(t, dirs[0], "for-each-ref", "--format=%(contents)", "refs/tags/"+name); !strings.Contains(out, "\n# kept") {
+			t.Errorf("TestRepositoryBackends: %s: comment lines were stripped: '%s'", backend, out)
+		}
+	}
+
+}
+
+func TestRepositorySignatures(t *testing.T) {
+
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	dir := newGitRepository(t)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "a")
+	runGit(t, dir, "tag", "v1.0.0")
+	runGit(t, dir, "tag", "-a", "-m", "Version v1.1.0", "v1.1.0")
+
+	// SSH signing key
+	key := filepath.Join(t.TempDir(), "release")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "tester", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("TestRepositorySignatures: could not create key: %s: %s", err.Error(), out)
+	}
+
+	repo, err := OpenRepository(dir, BackendExec)
+	if err != nil {
+		t.Fatalf("TestRepositorySignatures: %s", err.Error())
+	}
+	if err := repo.CreateTag("v2.0.0", "Version v2.0.0", "HEAD", &SignOptions{Key: key, Format: SignFormatSSH}); err != nil {
+		t.Fatalf("TestRepositorySignatures: could not sign tag: %s", err.Error())
+	}
+
+	// Tampered copy of the signed tag
+	tag := strings.Replace(runGit(t, dir, "cat-file", "tag", "v2.0.0"), "Version v2.0.0", "Version v2.0.1", 1)
+	cmd := exec.Command("git", "-C", dir, "mktag")
+	cmd.Stdin = strings.NewReader(tag + "\n")
+	object, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("TestRepositorySignatures: could not create tag object: %s", err.Error())
+	}
+	runGit(t, dir, "update-ref", "refs/tags/v2.0.1", strings.TrimSpace(string(object)))
+
+	verify := func(backend string, expected map[string]string) {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositorySignatures: %s", err.Error())
+		}
+		for name, status := range expected {
+			if got, err := repo.VerifyTag(name); err != nil || got != status {
+				t.Errorf("TestRepositorySignatures: %s: %s: expected %s, got %s (%v)", backend, name, status, got, err)
+			}
+		}
+	}
+
+	// Without allowed signers the signature cannot be checked
+	verify(BackendExec, map[string]string{"v1.0.0": SignatureUnsigned, "v1.1.0": SignatureUnsigned, "v2.0.0": SignatureUnknown})
+
+	signers := filepath.Join(t.TempDir(), "allowed_signers")
+	public, _ := ioutil.ReadFile(key + ".pub")
+	ioutil.WriteFile(signers, []byte("tester@example.com "+string(public)), 0644)
+	runGit(t, dir, "config", "gpg.ssh.allowedSignersFile", signers)
+
+	verify(BackendExec, map[string]string{"v2.0.0": SignatureGood, "v2.0.1": SignatureBad})
+	verify(BackendGoGit, map[string]string{"v1.0.0": SignatureUnsigned, "v1.1.0": SignatureUnsigned, "v2.0.0": SignatureUnknown})
+
+	// Configuration
+	runGit(t, dir, "config", ConfigSign, "true")
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, _ := OpenRepository(dir, backend)
+		if value, err := repo.Config(ConfigSign); err != nil || value != "true" {
+			t.Errorf("TestRepositorySignatures: %s: unexpected configuration '%s' (%v)", backend, value, err)
+		}
+		if value, err := repo.Config(ConfigSignKey); err != nil || value != "" {
+			t.Errorf("TestRepositorySignatures: %s: unexpected configuration '%s' (%v)", backend, value, err)
+		}
+		if backend == BackendGoGit && repo.CreateTag("v3.0.0", "", "HEAD", &SignOptions{}) == nil {
+			t.Errorf("TestRepositorySignatures: %s: expected an error for a signed tag", backend)
+		}
+	}
+
+}
+
+func TestRepositoryConfig(t *testing.T) {
+
+	dir := newGitRepository(t)
+	runGit(t, dir, "config", ConfigSign, "false")
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	writeFiles(t, home, map[string]string{".gitconfig": "[version]\n\tsign = true\n\ttagScheme = at\n"})
+
+	// Options of the repository win over global ones
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryConfig: %s", err.Error())
+		}
+		for key, expected := range map[string]string{ConfigSign: "false", ConfigTagScheme: "at", ConfigSignKey: ""} {
+			if value, err := repo.Config(key); err != nil || value != expected {
+				t.Errorf("TestRepositoryConfig: %s: got '%s' for %s, expected '%s' (%v)", backend, value, key, expected, err)
+			}
+		}
+	}
+
+}
+
+func TestRepositoryPush(t *testing.T) {
+
+	dir := newGitRepository(t)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "a")
+	runGit(t, dir, "tag", "junk")
+
+	bare := t.TempDir()
+	runGit(t, bare, "init", "-q", "--bare")
+	runGit(t, dir, "remote", "add", "origin", bare)
+
+	for i, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryPush: %s", err.Error())
+		}
+		if url, err := repo.Config("remote.origin.url"); err != nil || url != bare {
+			t.Errorf("TestRepositoryPush: %s: unexpected remote url '%s' (%v)", backend, url, err)
+		}
+
+		// Only the given tag is pushed
+		name := fmt.Sprintf("v%d.0.0", i+1)
+		repo.CreateTag(name, "Version "+name, "HEAD", nil)
+		if err := repo.PushTag("origin", name); err != nil {
+			t.Errorf("TestRepositoryPush: %s: could not push: %s", backend, err.Error())
+		}
+		if tags := runGit(t, bare, "tag", "-l"); tags != strings.Join([]string{"v1.0.0", name}[:i+1], "\n") {
+			t.Errorf("TestRepositoryPush: %s: unexpected remote tags '%s'", backend, tags)
+		}
+
+		// Existing tags are rejected
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "b")
+		if err := repo.DeleteTag(name); err != nil {
+			t.Errorf("TestRepositoryPush: %s: could not delete tag: %s", backend, err.Error())
+		}
+		repo.CreateTag(name, "Version "+name, "HEAD", nil)
+		if err := repo.PushTag("origin", name); err == nil {
+			t.Errorf("TestRepositoryPush: %s: expected a rejected push", backend)
+		}
+		if err := repo.PushTag("fork", name); err == nil {
+			t.Errorf("TestRepositoryPush: %s: expected an error for an unknown remote", backend)
+		}
+	}
+
+}
+
+func TestRepositoryRemoteTags(t *testing.T) {
+
+	upstream := newGitRepository(t)
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "a")
+	runGit(t, upstream, "tag", "v1.0.0")
+	runGit(t, upstream, "commit", "-q", "--allow-empty", "-m", "b")
+	runGit(t, upstream, "tag", "-a", "-m", "Version v1.1.0", "v1.1.0")
+	head := runGit(t, upstream, "rev-parse", "HEAD")
+
+	dir := newGitRepository(t)
+	runGit(t, dir, "remote", "add", "upstream", upstream)
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryRemoteTags: %s", err.Error())
+		}
+
+		// Remotes are given by name or URL
+		for _, remote := range []string{"upstream", upstream} {
+			tags, err := repo.RemoteTags(remote)
+			if err != nil {
+				t.Errorf("TestRepositoryRemoteTags: %s: %s: %s", backend, remote, err.Error())
+				continue
+			}
+			commits := map[string]string{}
+			for _, tag := range tags {
+				commits[tag.Name] = tag.Commit
+			}
+			if len(commits) != 2 || commits["v1.1.0"] != head || commits["v1.0.0"] == "" {
+				t.Errorf("TestRepositoryRemoteTags: %s: %s: unexpected tags %v", backend, remote, commits)
+			}
+		}
+
+		// Nothing is fetched
+		if tags, _ := repo.Tags(); len(tags) != 0 {
+			t.Errorf("TestRepositoryRemoteTags: %s: tags were fetched", backend)
+		}
+
+		if _, err := repo.RemoteTags(filepath.Join(upstream, "missing")); err == nil {
+			t.Errorf("TestRepositoryRemoteTags: %s: expected an error for a missing remote", backend)
+		}
+	}
+
+}
+
+func TestRepositoryCommitFiles(t *testing.T) {
+
+	dir := newGitRepository(t)
+	writeFiles(t, dir, map[string]string{"go.mod": "module a\n", "other.txt": "a\n"})
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "a")
+
+	for i, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryCommitFiles: %s", err.Error())
+		}
+
+		writeFiles(t, dir, map[string]string{"untracked.txt": "a\n"})
+		if dirty, err := repo.Dirty(); err != nil || dirty {
+			t.Errorf("TestRepositoryCommitFiles: %s: untracked files make the tree dirty (%v)", backend, err)
+		}
+		writeFiles(t, dir, map[string]string{"go.mod": fmt.Sprintf("module a/v%d\n", i+2)})
+		if dirty, err := repo.Dirty(); err != nil || !dirty {
+			t.Errorf("TestRepositoryCommitFiles: %s: expected a dirty tree (%v)", backend, err)
+		}
+
+		commit, err := repo.CommitFiles("update "+backend, []string{filepath.Join(dir, "go.mod")})
+		if err != nil {
+			t.Errorf("TestRepositoryCommitFiles: %s: %s", backend, err.Error())
+			continue
+		}
+		if commit.Message != "update "+backend || runGit(t, dir, "rev-parse", "HEAD") != commit.Hash {
+			t.Errorf("TestRepositoryCommitFiles: %s: unexpected commit %+v", backend, commit)
+		}
+		if files := runGit(t, dir, "show", "--name-only", "--format=", "HEAD"); files != "go.mod" {
+			t.Errorf("TestRepositoryCommitFiles: %s: unexpected files '%s'", backend, files)
+		}
+		if dirty, _ := repo.Dirty(); dirty {
+			t.Errorf("TestRepositoryCommitFiles: %s: tree is dirty after the commit", backend)
+		}
+	}
+
+}
+
+func TestRepositoryCommitsPaths(t *testing.T) {
+
+	dir := newGitRepository(t)
+	for _, file := range []string{"api/a.go", "web/w.go", "api/sub/b.go", "apiary.txt"} {
+		writeFiles(t, dir, map[string]string{file: file})
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-q", "-m", file)
+	}
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryCommitsPaths: %s", err.Error())
+		}
+
+		commits, err := repo.Commits("", "HEAD", "api")
+		messages := []string{}
+		for _, commit := range commits {
+			messages = append(messages, commit.Message)
+		}
+		if err != nil || strings.Join(messages, " ") != "api/sub/b.go api/a.go" {
+			t.Errorf("TestRepositoryCommitsPaths: %s: unexpected commits %v (%v)", backend, messages, err)
+		}
+	}
+
+}
+
+func TestRepositoryTagRefs(t *testing.T) {
+
+	dir := newGitRepository(t)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "a")
+	runGit(t, dir, "tag", "v1.0.0-rc.2")
+	runGit(t, dir, "tag", "-a", "-m", "Version v1.0.0\nof the first paragraph\n\nNotes", "v1.0.0")
+	runGit(t, dir, "tag", "sub/dir/v0.1.0")
+	runGit(t, dir, "branch", "release-v2.0.0")
+	runGit(t, dir, "tag", "tree", "HEAD^{tree}")
+	head := runGit(t, dir, "rev-parse", "HEAD")
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := OpenRepository(dir, backend)
+		if err != nil {
+			t.Fatalf("TestRepositoryTagRefs: %s", err.Error())
+		}
+
+		versions, err := GetVersions(repo, TieBreakNone)
+		if err != nil {
+			t.Fatalf("TestRepositoryTagRefs: %s: %s", backend, err.Error())
+		}
+
+		// One version per tag, all on the same commit
+		tags := []string{}
+		for _, version := range versions.versions {
+			tags = append(tags, version.Tag)
+			if version.Commit != head {
+				t.Errorf("TestRepositoryTagRefs: %s: %s points to %s instead of %s", backend, version.Tag, version.Commit, head)
+			}
+		}
+		if strings.Join(tags, " ") != "v1.0.0 v1.0.0-rc.2 sub/dir/v0.1.0" {
+			t.Errorf("TestRepositoryTagRefs: %s: unexpected versions %v", backend, tags)
+		}
+
+		// Only annotated tags have a tagger and a message, messages are
+		// kept line by line
+		for _, version := range versions.versions {
+			annotated := version.Tag == "v1.0.0"
+			if version.Annotated != annotated {
+				t.Errorf("TestRepositoryTagRefs: %s: %s annotated is %t", backend, version.Tag, version.Annotated)
+			}
+			if annotated && (version.Tagger != "Tester <tester@example.com>" || version.Message != "Version v1.0.0\nof the first paragraph\n\nNotes") {
+				t.Errorf("TestRepositoryTagRefs: %s: unexpected tag metadata '%s' '%s'", backend, version.Tagger, version.Message)
+			}
+			if !annotated && (version.Tagger != "" || version.Message != "") {
+				t.Errorf("TestRepositoryTagRefs: %s: lightweight tag %s has metadata", backend, version.Tag)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackendFake is the name of the in-memory test backend
+const BackendFake = "fake"
+
+// fakeRepositories holds the fake repositories by path, used by the fake backend
+var fakeRepositories = map[string]*fakeRepository{}
+
+func init() {
+	backends[BackendFake] = func(path string) (Repository, error) {
+		repo, ok := fakeRepositories[path]
+		if !ok {
+			return nil, fmt.Errorf("no fake repository at '%s'", path)
+		}
+		return repo, nil
+	}
+}
+
+// fakeRepository is an in-memory implementation of Repository. Commits,
+// tags and branches are constructed programmatically
+type fakeRepository struct {
+	path     string
+	commits  []*Commit
+	files    map[string][]string // changed files by commit hash
+	parents  map[string]string
+	tags     []*Tag
+	messages map[string]string // tag annotations by tag name
+	signed   map[string]string // signature states by tag name
+	config   map[string]string
+	remotes  map[string]map[string]string // tags pushed to remotes, by remote and tag name
+	branches map[string]string
+	branch   string
+	dirty    bool  // uncommitted changes of tracked files
+	failure  error // returned by CommitFiles if set
+	head     string
+	date     time.Time
+}
+
+// newFakeRepository creates an empty fake repository on the master branch
+// and registers it with the fake backend
+func newFakeRepository(path string) *fakeRepository {
+	repo := &fakeRepository{
+		path:     path,
+		commits:  []*Commit{},
+		files:    map[string][]string{},
+		parents:  map[string]string{},
+		tags:     []*Tag{},
+		messages: map[string]string{},
+		signed:   map[string]string{},
+		config:   map[string]string{},
+		remotes:  map[string]map[string]string{"origin": {}},
+		branches: map[string]string{},
+		branch:   "master",
+		date:     time.Date(2017, 9, 7, 12, 0, 0, 0, time.UTC),
+	}
+	fakeRepositories[path] = repo
+	return repo
+}
+
+// commit adds a commit on top of HEAD and advances the active branch.
+// Every commit is an hour younger than the previous one and committed
+// half an hour after it was authored, like a rebased commit. The first line
+// of the message is the subject, the rest is the body. Files are recorded
+// as changed by the commit
+func (r *fakeRepository) commit(message string, files ...string) *Commit {
+	r.date = r.date.Add(time.Hour)
+	lines := strings.SplitN(message, "\n", 2)
+	commit := &Commit{
+		Hash:       fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%s/%d/%s", r.path, len(r.commits), message)))),
+		Date:       r.date,
+		CommitDate: r.date.Add(30 * time.Minute),
+		Author:     "Tester",
+		Message:    lines[0],
+	}
+	if len(lines) == 2 {
+		commit.Body = strings.TrimSpace(lines[1])
+	}
+	r.commits = append(r.commits, commit)
+	r.files[commit.Hash] = files
+	r.parents[commit.Hash] = r.head
+	r.head = commit.Hash
+	if r.branch != "" {
+		r.branches[r.branch] = commit.Hash
+	}
+	return commit
+}
+
+// tag tags HEAD
+func (r *fakeRepository) tag(names ...string) {
+	for _, name := range names {
+		if err := r.CreateTag(name, "", r.head, nil); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// checkout switches the active branch, creating it at HEAD if necessary
+func (r *fakeRepository) checkout(branch string) {
+	if hash, ok := r.branches[branch]; ok {
+		r.head = hash
+	} else {
+		r.branches[branch] = r.head
+	}
+	r.branch = branch
+}
+
+// find returns the commit a revision resolves to
+func (r *fakeRepository) find(rev string) (*Commit, error) {
+
+	if rev == "HEAD" {
+		rev = r.head
+	} else if hash, ok := r.branches[rev]; ok {
+		rev = hash
+	} else {
+		for _, tag := range r.tags {
+			if tag.Name == rev {
+				rev = tag.Commit
+			}
+		}
+	}
+
+	for _, commit := range r.commits {
+		if rev != "" && strings.HasPrefix(commit.Hash, rev) {
+			return commit, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown revision '%s'", rev)
+}
+
+// Path implements Repository.Path
+func (r *fakeRepository) Path() string {
+	return r.path
+}
+
+// Tags implements Repository.Tags
+func (r *fakeRepository) Tags() ([]*Tag, error) {
+	return r.tags, nil
+}
+
+// Head implements Repository.Head
+func (r *fakeRepository) Head() (*Commit, error) {
+	return r.find("HEAD")
+}
+
+// Commit implements Repository.Commit
+func (r *fakeRepository) Commit(rev string) (*Commit, error) {
+	return r.find(rev)
+}
+
+// Commits implements Repository.Commits
+func (r *fakeRepository) Commits(from, to string, paths ...string) ([]*Commit, error) {
+
+	// ancestors lists a commit and its ancestors, newest first
+	ancestors := func(rev string) ([]string, error) {
+		commit, err := r.find(rev)
+		if err != nil {
+			return nil, err
+		}
+		hashes := []string{}
+		for hash := commit.Hash; hash != ""; hash = r.parents[hash] {
+			hashes = append(hashes, hash)
+		}
+		return hashes, nil
+	}
+
+	exclude := map[string]bool{}
+	if from != "" {
+		hashes, err := ancestors(from)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range hashes {
+			exclude[hash] = true
+		}
+	}
+
+	hashes, err := ancestors(to)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*Commit{}
+	for _, hash := range hashes {
+		if !exclude[hash] && r.changes(hash, paths) {
+			commit, _ := r.find(hash)
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits, nil
+}
+
+// changes reports whether a commit changed files in one of paths, or
+// whether no paths are given
+func (r *fakeRepository) changes(hash string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, file := range r.files[hash] {
+		if inPaths(file, paths) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTag implements Repository.CreateTag
+func (r *fakeRepository) CreateTag(name, message, rev string, sign *SignOptions) error {
+
+	for _, tag := range r.tags {
+		if tag.Name == name {
+			return fmt.Errorf("tag '%s' already exists", name)
+		}
+	}
+
+	commit, err := r.find(rev)
+	if err != nil {
+		return err
+	}
+
+	r.messages[name] = message
+	if sign != nil {
+		r.signed[name] = SignatureGood
+	}
+	r.tags = append(r.tags, &Tag{
+		Name:      name,
+		Commit:    commit.Hash,
+		Date:      commit.Date,
+		TagDate:   r.date,
+		Annotated: true,
+		Tagger:    "Fake <fake@example.com>",
+		Message:   message,
+	})
+
+	return nil
+}
+
+// CommitFiles implements Repository.CommitFiles. The files must exist and
+// are recorded as the body of the new commit
+func (r *fakeRepository) CommitFiles(message string, paths []string) (*Commit, error) {
+	if r.failure != nil {
+		return nil, r.failure
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("pathspec '%s' did not match any files", path)
+		}
+	}
+	r.dirty = false
+	return r.commit(message + "\n\n" + strings.Join(paths, "\n")), nil
+}
+
+// Dirty implements Repository.Dirty
+func (r *fakeRepository) Dirty() (bool, error) {
+	return r.dirty, nil
+}
+
+// DeleteTag implements Repository.DeleteTag
+func (r *fakeRepository) DeleteTag(name string) error {
+	for i, tag := range r.tags {
+		if tag.Name == name {
+			r.tags = append(r.tags[:i], r.tags[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("tag '%s' not found", name)
+}
+
+// PushTag implements Repository.PushTag. Pushes of tags that already exist
+// on the remote with a different commit are rejected
+func (r *fakeRepository) PushTag(remote, name string) error {
+
+	tags, ok := r.remotes[remote]
+	if !ok {
+		return fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+
+	commit, err := r.find(name)
+	if err != nil {
+		return err
+	}
+
+	if pushed, ok := tags[name]; ok && pushed != commit.Hash {
+		return fmt.Errorf("! [rejected] %s -> %s (already exists)", name, name)
+	}
+	tags[name] = commit.Hash
+
+	return nil
+}
+
+// RemoteTags implements Repository.RemoteTags. Remotes are given by name
+// or by their "fake://name" URL
+func (r *fakeRepository) RemoteTags(remote string) ([]*Tag, error) {
+
+	pushed, ok := r.remotes[strings.TrimPrefix(remote, "fake://")]
+	if !ok {
+		return nil, fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+
+	names := []string{}
+	for name := range pushed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tags := []*Tag{}
+	for _, name := range names {
+		tags = append(tags, &Tag{Name: name, Commit: pushed[name]})
+	}
+
+	return tags, nil
+}
+
+// VerifyTag implements Repository.VerifyTag
+func (r *fakeRepository) VerifyTag(name string) (string, error) {
+	if status, ok := r.signed[name]; ok {
+		return status, nil
+	}
+	return SignatureUnsigned, nil
+}
+
+// Config implements Repository.Config
+func (r *fakeRepository) Config(key string) (string, error) {
+	for remote := range r.remotes {
+		if key == "remote."+remote+".url" {
+			return "fake://" + remote, nil
+		}
+	}
+	return r.config[key], nil
+}
+
+// Branch implements Repository.Branch
+func (r *fakeRepository) Branch() (string, error) {
+	if r.branch == "" {
+		return "", fmt.Errorf("could not determine active branch")
+	}
+	return r.branch, nil
+}
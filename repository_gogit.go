@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// goGitRepository implements Repository using the pure-Go go-git library,
+// i.e. it does not require git to be installed
+type goGitRepository struct {
+	path string
+	repo *git.Repository
+}
+
+// NewGoGitRepository creates a go-git backed repository
+func NewGoGitRepository(path string) (Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not open repository '%s': %s", path, err.Error())
+	}
+	return &goGitRepository{path: path, repo: repo}, nil
+}
+
+// Path implements Repository.Path
+func (r *goGitRepository) Path() string {
+	return r.path
+}
+
+// Tags implements Repository.Tags
+func (r *goGitRepository) Tags() ([]*Tag, error) {
+
+	refs, err := r.repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags: %s", err.Error())
+	}
+
+	tags := []*Tag{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+
+		tag := &Tag{Name: ref.Name().Short()}
+
+		// Annotated tags point to a tag object, lightweight tags to a commit
+		var commit *object.Commit
+		if tagObj, err := r.repo.TagObject(ref.Hash()); err == nil {
+			if commit, err = tagObj.Commit(); err != nil {
+				return nil
+			}
+			tag.TagDate = tagObj.Tagger.When
+			tag.Annotated = true
+			tag.Tagger = fmt.Sprintf("%s <%s>", tagObj.Tagger.Name, tagObj.Tagger.Email)
+			tag.Message = strings.TrimSpace(tagObj.Message)
+		} else if commit, err = r.repo.CommitObject(ref.Hash()); err == nil {
+			tag.TagDate = commit.Committer.When
+		} else {
+			return nil
+		}
+
+		tag.Commit = commit.Hash.String()
+		tag.Date = commit.Author.When
+		tags = append(tags, tag)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags: %s", err.Error())
+	}
+
+	return tags, nil
+}
+
+// Head implements Repository.Head
+func (r *goGitRepository) Head() (*Commit, error) {
+	return r.Commit("HEAD")
+}
+
+// Commit implements Repository.Commit
+func (r *goGitRepository) Commit(rev string) (*Commit, error) {
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve '%s': %s", rev, err.Error())
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit '%s': %s", rev, err.Error())
+	}
+
+	return newGoGitCommit(commit), nil
+}
+
+// Commits implements Repository.Commits
+func (r *goGitRepository) Commits(from, to string, paths ...string) ([]*Commit, error) {
+
+	// Commits reachable from the lower bound are excluded
+	exclude := map[plumbing.Hash]bool{}
+	if from != "" {
+		err := r.walk(from, nil, func(commit *object.Commit) error {
+			exclude[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not list commits: %s", err.Error())
+		}
+	}
+
+	commits := []*Commit{}
+	err := r.walk(to, paths, func(commit *object.Commit) error {
+		if !exclude[commit.Hash] {
+			commits = append(commits, newGoGitCommit(commit))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits: %s", err.Error())
+	}
+
+	return commits, nil
+}
+
+// walk calls fn for every commit reachable from a revision, newest first.
+// Given paths limit the walk to the commits changing files in them
+func (r *goGitRepository) walk(rev string, paths []string, fn func(*object.Commit) error) error {
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return fmt.Errorf("could not resolve '%s': %s", rev, err.Error())
+	}
+
+	opts := &git.LogOptions{From: *hash, Order: git.LogOrderCommitterTime}
+	if len(paths) > 0 {
+		opts.PathFilter = func(file string) bool { return inPaths(file, paths) }
+	}
+
+	iter, err := r.repo.Log(opts)
+	if err != nil {
+		return err
+	}
+
+	return iter.ForEach(fn)
+}
+
+// newGoGitCommit converts a go-git commit
+func newGoGitCommit(commit *object.Commit) *Commit {
+
+	lines := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)
+	body := ""
+	if len(lines) == 2 {
+		body = strings.TrimSpace(lines[1])
+	}
+
+	return &Commit{
+		Hash:       commit.Hash.String(),
+		Date:       commit.Author.When,
+		CommitDate: commit.Committer.When,
+		Author:     commit.Author.Name,
+		Message:    lines[0],
+		Body:       body,
+	}
+}
+
+// CreateTag implements Repository.CreateTag
+func (r *goGitRepository) CreateTag(name, message, rev string, sign *SignOptions) error {
+
+	if sign != nil {
+		return fmt.Errorf("signed tags are only supported by the exec backend")
+	}
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return fmt.Errorf("could not resolve '%s': %s", rev, err.Error())
+	}
+
+	_, err = r.repo.CreateTag(name, *hash, &git.CreateTagOptions{Message: message})
+
+	return err
+}
+
+// CommitFiles implements Repository.CommitFiles
+func (r *goGitRepository) CommitFiles(message string, paths []string) (*Commit, error) {
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("could not open worktree: %s", err.Error())
+	}
+
+	// go-git expects paths relative to the worktree
+	root, err := filepath.EvalSymlinks(wt.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := wt.Add(filepath.ToSlash(rel)); err != nil {
+			return nil, fmt.Errorf("could not add '%s': %s", rel, err.Error())
+		}
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not commit: %s", err.Error())
+	}
+
+	return r.Commit(hash.String())
+}
+
+// Dirty implements Repository.Dirty
+func (r *goGitRepository) Dirty() (bool, error) {
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("could not open worktree: %s", err.Error())
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("could not get status: %s", err.Error())
+	}
+
+	for _, file := range status {
+		if file.Worktree != git.Untracked || file.Staging != git.Untracked {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// DeleteTag implements Repository.DeleteTag
+func (r *goGitRepository) DeleteTag(name string) error {
+	return r.repo.DeleteTag(name)
+}
+
+// PushTag implements Repository.PushTag
+func (r *goGitRepository) PushTag(remote, name string) error {
+
+	ref := "refs/tags/" + name
+	err := r.repo.Push(&git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":" + ref)},
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+
+	return err
+}
+
+// RemoteTags implements Repository.RemoteTags. Unknown remote names are
+// treated as URLs
+func (r *goGitRepository) RemoteTags(remote string) ([]*Tag, error) {
+
+	rem, err := r.repo.Remote(remote)
+	if err == git.ErrRemoteNotFound {
+		rem = git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "anonymous", URLs: []string{remote}})
+	} else if err != nil {
+		return nil, err
+	}
+
+	list, err := rem.List(&git.ListOptions{PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := [][2]string{}
+	for _, ref := range list {
+		refs = append(refs, [2]string{ref.Name().String(), ref.Hash().String()})
+	}
+
+	return remoteTags(refs), nil
+}
+
+// VerifyTag implements Repository.VerifyTag. go-git has no access to the
+// user's keys, hence signatures are never checked
+func (r *goGitRepository) VerifyTag(name string) (string, error) {
+
+	ref, err := r.repo.Tag(name)
+	if err != nil {
+		return "", fmt.Errorf("could not get tag '%s': %s", name, err.Error())
+	}
+
+	tag, err := r.repo.TagObject(ref.Hash())
+	if err != nil || tag.PGPSignature == "" {
+		return SignatureUnsigned, nil
+	}
+
+	return SignatureUnknown, nil
+}
+
+// Config implements Repository.Config. Options of the repository take
+// precedence over the global (~/.gitconfig) and system ones, like in git
+func (r *goGitRepository) Config(key string) (string, error) {
+
+	// section.option or section.subsection.option
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	if first < 0 {
+		return "", fmt.Errorf("invalid key '%s'", key)
+	}
+
+	// Scoped configurations only merge known fields, raw options of
+	// the global and system files are read separately
+	local, err := r.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("could not read configuration: %s", err.Error())
+	}
+	configs := []*config.Config{local}
+	for _, scope := range []config.Scope{config.GlobalScope, config.SystemScope} {
+		cfg, err := config.LoadConfig(scope)
+		if err != nil {
+			return "", fmt.Errorf("could not read configuration: %s", err.Error())
+		}
+		configs = append(configs, cfg)
+	}
+
+	for _, cfg := range configs {
+		section, value := cfg.Raw.Section(key[:first]), ""
+		if first == last {
+			value = section.Option(key[last+1:])
+		} else {
+			value = section.Subsection(key[first+1 : last]).Option(key[last+1:])
+		}
+		if value != "" {
+			return value, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Branch implements Repository.Branch
+func (r *goGitRepository) Branch() (string, error) {
+
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("could not get HEAD: %s", err.Error())
+	}
+
+	if !head.Name().IsBranch() {
+		return fmt.Sprintf("(HEAD detached at %s)", head.Hash().String()[:7]), nil
+	}
+
+	return head.Name().Short(), nil
+}
@@ -0,0 +1,338 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a set of version ranges in the syntax of npm (and, except
+// for bare versions, Cargo), e.g. ">=1.2.0 <2.0.0 || ~2.3". Ranges are
+// separated by "||", a version satisfies a range if it satisfies all of
+// its comparators. Supported comparators are:
+//
+//	1.2.3, =1.2.3        exactly 1.2.3
+//	>1.2.3, >=1.2.3      larger (or equal)
+//	<1.2.3, <=1.2.3      lower (or equal)
+//	~1.2.3               patch updates: >=1.2.3 <1.3.0
+//	^1.2.3               compatible updates: >=1.2.3 <2.0.0, ^0.2.3 is <0.3.0
+//	1.2.x, 1.*, 1, *     wildcards: >=1.2.0 <1.3.0, >=1.0.0 <2.0.0, any version
+//	1.2.3 - 2.3          hyphen ranges: >=1.2.3 <2.4.0
+//
+// Comparators may also be separated by commas. Pre-releases only satisfy
+// a range if one of its comparators is a pre-release of the same
+// major.minor.patch version (e.g. >=1.2.3-rc.1 matches 1.2.3-rc.2, but not
+// 1.2.4-rc.1), unless IncludePrerelease is set. Build metadata is ignored.
+type Constraint struct {
+	IncludePrerelease bool // pre-releases satisfy ranges like releases
+
+	input  string
+	ranges [][]*comparator
+}
+
+// comparator compares versions with a single version
+type comparator struct {
+	op      string // <, <=, >, >= or =
+	version *Version
+}
+
+// partial is a version with optional wildcards, e.g. 1.2.x. Only the
+// first n release fields are set
+type partial struct {
+	version *Version
+	n       int
+}
+
+var (
+	// Hyphen range, e.g. "1.2.3 - 2.3.4"
+	hyphenRange = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+
+	// Whitespace between an operator and its version, e.g. ">= 1.2.3"
+	operatorSpace = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)\s+`)
+)
+
+// ParseConstraint parses a constraint, see Constraint
+func ParseConstraint(s string) (*Constraint, error) {
+
+	c := &Constraint{input: s}
+	for _, r := range strings.Split(s, "||") {
+
+		comparators, err := parseRange(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint '%s': %s", s, err.Error())
+		}
+		c.ranges = append(c.ranges, comparators)
+	}
+
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics if the constraint
+// cannot be parsed
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(fmt.Sprintf("semver: %s", err.Error()))
+	}
+	return c
+}
+
+// String returns the constraint as it was parsed
+func (c *Constraint) String() string {
+	return c.input
+}
+
+// Ranges returns the ranges of the constraint in terms of the basic
+// comparators (<, <=, >, >=, =), e.g. ">=1.2.3 <2.0.0-0" for "^1.2.3". An
+// upper bound of x.y.z-0 excludes x.y.z and all of its pre-releases
+func (c *Constraint) Ranges() []string {
+
+	ranges := []string{}
+	for _, comparators := range c.ranges {
+		parts := []string{}
+		for _, comp := range comparators {
+			parts = append(parts, comp.op+comp.version.String())
+		}
+		ranges = append(ranges, strings.Join(parts, " "))
+	}
+
+	return ranges
+}
+
+// Check returns true if version v satisfies any of the ranges
+func (c *Constraint) Check(v *Version) bool {
+
+	for _, comparators := range c.ranges {
+		if c.checkRange(comparators, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRange returns true if version v satisfies all the comparators of
+// a range, taking the pre-release rule into account
+func (c *Constraint) checkRange(comparators []*comparator, v *Version) bool {
+
+	for _, comp := range comparators {
+		if !comp.check(v) {
+			return false
+		}
+	}
+
+	if v.Special == "" || c.IncludePrerelease {
+		return true
+	}
+
+	// Pre-releases need a pre-release comparator of the same version
+	for _, comp := range comparators {
+		w := comp.version
+		if w.Special != "" && w.Major == v.Major && w.Minor == v.Minor && w.Patch == v.Patch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check compares version v with the comparator's version
+func (comp *comparator) check(v *Version) bool {
+
+	c := Compare(v, comp.version)
+	switch comp.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+
+	return c == 0
+}
+
+// parseRange parses a range of space or comma separated comparators. An
+// empty range matches any version
+func parseRange(r string) ([]*comparator, error) {
+
+	if m := hyphenRange.FindStringSubmatch(r); m != nil {
+		from, err := parsePartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+		return append(from.lower(), to.upper()...), nil
+	}
+
+	r = operatorSpace.ReplaceAllString(strings.Replace(r, ",", " ", -1), "$1")
+
+	comparators := []*comparator{}
+	for _, field := range strings.Fields(r) {
+		desugared, err := parseComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, desugared...)
+	}
+
+	if len(comparators) == 0 {
+		comparators = append(comparators, &comparator{">=", &Version{}})
+	}
+
+	return comparators, nil
+}
+
+// parseComparator parses a single comparator and translates it into basic
+// comparators
+func parseComparator(s string) ([]*comparator, error) {
+
+	op := ""
+	for _, prefix := range []string{"<=", ">=", "<", ">", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+
+	p, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=":
+		if p.n == 3 {
+			return []*comparator{{"=", p.version}}, nil
+		}
+		return append(p.lower(), p.upper()...), nil
+	case ">=":
+		return p.lower(), nil
+	case "<=":
+		return p.upper(), nil
+	case ">":
+		if p.n == 3 {
+			return []*comparator{{">", p.version}}, nil
+		}
+		if p.n == 0 {
+			return []*comparator{{"<", &Version{Special: "0"}}}, nil
+		}
+		// The release itself, since lower bounds admit no pre-releases
+		bound := p.next(p.n)
+		bound.Special = ""
+		return []*comparator{{">=", bound}}, nil
+	case "<":
+		if p.n == 3 {
+			return []*comparator{{"<", p.version}}, nil
+		}
+		bound := &Version{Major: p.version.Major, Minor: p.version.Minor, Special: "0"}
+		return []*comparator{{"<", bound}}, nil
+	case "~":
+		if p.n == 3 {
+			return append(p.lower(), &comparator{"<", p.next(2)}), nil
+		}
+		return append(p.lower(), p.upper()...), nil
+	}
+
+	// Caret: the leftmost non-zero field must not change
+	switch {
+	case p.n == 0:
+		return p.lower(), nil
+	case p.version.Major > 0 || p.n == 1:
+		return append(p.lower(), &comparator{"<", p.next(1)}), nil
+	case p.version.Minor > 0 || p.n == 2:
+		return append(p.lower(), &comparator{"<", p.next(2)}), nil
+	}
+
+	return append(p.lower(), &comparator{"<", p.next(3)}), nil
+}
+
+// parsePartial parses a version with optional wildcards (x, X or *) or
+// missing fields. Pre-release and build metadata require all the release
+// fields
+func parsePartial(s string) (*partial, error) {
+
+	if strings.ContainsAny(s, "-+") {
+		v, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return &partial{version: v, n: 3}, nil
+	}
+
+	fields := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("invalid version '%s': too many fields", s)
+	}
+
+	p := &partial{version: &Version{}}
+	for i, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			for _, rest := range fields[i+1:] {
+				if rest != "x" && rest != "X" && rest != "*" {
+					return nil, fmt.Errorf("invalid version '%s': wildcards must not be followed by numbers", s)
+				}
+			}
+			break
+		}
+		if !isNumeric(field) || len(field) > 1 && field[0] == '0' {
+			return nil, fmt.Errorf("invalid version '%s': '%s' is not a number", s, field)
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version '%s': number overflows int", s)
+		}
+		switch i {
+		case 0:
+			p.version.Major = n
+		case 1:
+			p.version.Minor = n
+		case 2:
+			p.version.Patch = n
+		}
+		p.n++
+	}
+
+	return p, nil
+}
+
+// lower returns the lower bound of the versions matching the partial
+func (p *partial) lower() []*comparator {
+	return []*comparator{{">=", p.version}}
+}
+
+// upper returns the upper bound of the versions matching the partial.
+// There is none if all the fields are wildcards
+func (p *partial) upper() []*comparator {
+	switch p.n {
+	case 0:
+		return nil
+	case 3:
+		return []*comparator{{"<=", p.version}}
+	}
+	return []*comparator{{"<", p.next(p.n)}}
+}
+
+// next returns the lowest version (i.e. pre-release 0) following the
+// partial once the field at position n (1 - major, 2 - minor, 3 - patch)
+// is increased
+func (p *partial) next(n int) *Version {
+
+	v := &Version{Major: p.version.Major, Special: "0"}
+	switch n {
+	case 1:
+		v.Major++
+	case 2:
+		v.Minor = p.version.Minor + 1
+	case 3:
+		v.Minor, v.Patch = p.version.Minor, p.version.Patch+1
+	}
+
+	return v
+}
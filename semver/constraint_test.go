@@ -0,0 +1,96 @@
+package semver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConstraint(t *testing.T) {
+
+	tests := []struct {
+		in     string
+		ranges string
+		valid  bool
+	}{
+		{"1.2.3", "=v1.2.3", true},
+		{"=v1.2.3", "=v1.2.3", true},
+		{">= 1.2.0 <2.0.0 || ~2.3", ">=v1.2.0 <v2.0.0 || >=v2.3.0 <v2.4.0-0", true},
+		{">=1.2, <1.5", ">=v1.2.0 <v1.5.0-0", true},
+		{"^1.2.3", ">=v1.2.3 <v2.0.0-0", true},
+		{"^0.2.3", ">=v0.2.3 <v0.3.0-0", true},
+		{"^0.0.3", ">=v0.0.3 <v0.0.4-0", true},
+		{"^0.0", ">=v0.0.0 <v0.1.0-0", true},
+		{"^0.x", ">=v0.0.0 <v1.0.0-0", true},
+		{"^1.2.x", ">=v1.2.0 <v2.0.0-0", true},
+		{"~1.2.3-rc.1", ">=v1.2.3-rc.1 <v1.3.0-0", true},
+		{"~1", ">=v1.0.0 <v2.0.0-0", true},
+		{"1.2.x", ">=v1.2.0 <v1.3.0-0", true},
+		{"1.*", ">=v1.0.0 <v2.0.0-0", true},
+		{"*", ">=v0.0.0", true},
+		{"", ">=v0.0.0", true},
+		{">1.2", ">=v1.3.0", true},
+		{"<1.2", "<v1.2.0-0", true},
+		{"<=1.2", "<v1.3.0-0", true},
+		{"1.2.3 - 2.3", ">=v1.2.3 <v2.4.0-0", true},
+		{"1.2 - 2.3.4", ">=v1.2.0 <=v2.3.4", true},
+		{"1.x.3", "", false},
+		{"1.2.3.4", "", false},
+		{">=01.2.3", "", false},
+		{"~>1.2", "", false},
+		{"1.2.3 -", "", false},
+		{"latest", "", false},
+	}
+
+	for i, test := range tests {
+		c, err := ParseConstraint(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("TestParseConstraint: test %d failed: unexpected error state: %v", i+1, err)
+			continue
+		}
+		if test.valid && strings.Join(c.Ranges(), " || ") != test.ranges {
+			t.Errorf("TestParseConstraint: test %d failed: got '%s', expected '%s'", i+1, strings.Join(c.Ranges(), " || "), test.ranges)
+		}
+	}
+
+}
+
+func TestConstraintCheck(t *testing.T) {
+
+	tests := []struct {
+		constraint string
+		version    string
+		prerelease bool
+		result     bool
+	}{
+		{">=1.2.0 <2.0.0 || ~2.3", "v1.2.0", false, true},
+		{">=1.2.0 <2.0.0 || ~2.3", "v1.9.9+build", false, true},
+		{">=1.2.0 <2.0.0 || ~2.3", "v2.0.0", false, false},
+		{">=1.2.0 <2.0.0 || ~2.3", "v2.3.7", false, true},
+		{">=1.2.0 <2.0.0 || ~2.3", "v2.4.0", false, false},
+		{"^1", "v1.99.0", false, true},
+		{"^1", "v2.0.0-rc.1", false, false},
+		{"^1", "v2.0.0-rc.1", true, false},
+		{"^1", "v1.5.0-rc.1", false, false},
+		{"^1", "v1.5.0-rc.1", true, true},
+		{">=1.2.3-rc.1 <2", "v1.2.3-rc.2", false, true},
+		{">=1.2.3-rc.1 <2", "v1.2.4-rc.1", false, false},
+		{">=1.2.3-rc.1 <2", "v1.2.3-alpha", false, false},
+		{">1.2", "v1.3.0-rc.1", false, false},
+		{"1.2.3", "v1.2.3+build", false, true},
+		{"*", "v0.0.0", false, true},
+		{"*", "v1.0.0-rc.1", false, false},
+		{">*", "v1.0.0", false, false},
+		{"1.2.3 - 2.3", "v2.3.9", false, true},
+		{"1.2.3 - 2.3", "v2.4.0", false, false},
+		{"1.2.3 - 2.3.4", "v2.3.5", false, false},
+	}
+
+	for i, test := range tests {
+		c := MustParseConstraint(test.constraint)
+		c.IncludePrerelease = test.prerelease
+		if result := c.Check(MustParse(test.version)); result != test.result {
+			t.Errorf("TestConstraintCheck: test %d failed: %s satisfies '%s': got %t, expected %t", i+1, test.version, test.constraint, result, test.result)
+		}
+	}
+
+}
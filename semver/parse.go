@@ -0,0 +1,199 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes why a string is not a valid semantic version
+type ParseError struct {
+	Input string // string being parsed
+	Pos   int    // byte offset of the offending character
+	Field string // major, minor, patch, special or build
+	Msg   string // description of the problem
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid version '%s' at position %d: %s", e.Input, e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("invalid version '%s' at position %d: %s (%s)", e.Input, e.Pos, e.Msg, e.Field)
+}
+
+// parser is a single-use parser of the semver 2.0.0 grammar
+type parser struct {
+	in  string
+	pos int
+}
+
+// Parse parses a version string, e.g. v1.2.3-rc.1+1504795241. The leading
+// "v" is optional. The full semver 2.0.0 grammar is supported, i.e.
+// pre-release and build identifiers consist of [0-9A-Za-z-] and numeric
+// identifiers must not have leading zeros.
+func Parse(s string) (*Version, error) {
+	p := &parser{in: s}
+	return p.parse()
+}
+
+// parse parses the whole input
+func (p *parser) parse() (*Version, error) {
+
+	if p.in == "" {
+		return nil, p.errorf("", "empty string")
+	}
+
+	// Optional prefix
+	if p.in[0] == 'v' {
+		p.pos++
+	}
+
+	v := &Version{}
+	var err error
+
+	// Release version
+	if v.Major, err = p.number("major"); err != nil {
+		return nil, err
+	}
+	if err = p.expect('.', "major"); err != nil {
+		return nil, err
+	}
+	if v.Minor, err = p.number("minor"); err != nil {
+		return nil, err
+	}
+	if err = p.expect('.', "minor"); err != nil {
+		return nil, err
+	}
+	if v.Patch, err = p.number("patch"); err != nil {
+		return nil, err
+	}
+
+	// Pre-release version
+	if p.peek() == '-' {
+		p.pos++
+		if v.Special, err = p.identifiers("special", true); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build metadata
+	if p.peek() == '+' {
+		p.pos++
+		if v.Build, err = p.identifiers("build", false); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.pos < len(p.in) {
+		return nil, p.errorf("", "unexpected character '%c'", p.in[p.pos])
+	}
+
+	return v, nil
+}
+
+// number parses a numeric release field
+func (p *parser) number(field string) (int, error) {
+
+	start := p.pos
+	for p.pos < len(p.in) && isDigit(p.in[p.pos]) {
+		p.pos++
+	}
+
+	digits := p.in[start:p.pos]
+	if digits == "" {
+		if p.pos < len(p.in) {
+			return 0, p.errorf(field, "expected a digit, got '%c'", p.in[p.pos])
+		}
+		return 0, p.errorf(field, "expected a digit, got end of string")
+	}
+	if len(digits) > 1 && digits[0] == '0' {
+		return 0, p.errorAt(start, field, "leading zero in numeric field")
+	}
+
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, p.errorAt(start, field, "number overflows int")
+	}
+
+	return n, nil
+}
+
+// identifiers parses dot-separated pre-release or build identifiers
+func (p *parser) identifiers(field string, numeric bool) (string, error) {
+
+	start := p.pos
+	for {
+		idStart := p.pos
+		for p.pos < len(p.in) && isIdentChar(p.in[p.pos]) {
+			p.pos++
+		}
+
+		id := p.in[idStart:p.pos]
+		if id == "" {
+			return "", p.errorf(field, "empty identifier")
+		}
+
+		// Numeric pre-release identifiers must not have leading zeros
+		if numeric && isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return "", p.errorAt(idStart, field, "leading zero in numeric identifier")
+		}
+
+		if p.peek() != '.' {
+			break
+		}
+		p.pos++
+	}
+
+	return p.in[start:p.pos], nil
+}
+
+// expect consumes the expected character
+func (p *parser) expect(c byte, field string) error {
+	if p.pos >= len(p.in) {
+		return p.errorf(field, "expected '%c', got end of string", c)
+	}
+	if p.in[p.pos] != c {
+		return p.errorf(field, "expected '%c', got '%c'", c, p.in[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// peek returns the current character or 0 at the end of input
+func (p *parser) peek() byte {
+	if p.pos >= len(p.in) {
+		return 0
+	}
+	return p.in[p.pos]
+}
+
+// errorf creates a parse error at the current position
+func (p *parser) errorf(field, format string, a ...interface{}) error {
+	return p.errorAt(p.pos, field, format, a...)
+}
+
+// errorAt creates a parse error at the given position
+func (p *parser) errorAt(pos int, field, format string, a ...interface{}) error {
+	return &ParseError{
+		Input: p.in,
+		Pos:   pos,
+		Field: field,
+		Msg:   fmt.Sprintf(format, a...),
+	}
+}
+
+// isDigit checks whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar checks whether c is allowed in pre-release and build identifiers
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-'
+}
+
+// isNumeric checks whether an identifier consists of digits only
+func isNumeric(id string) bool {
+	return id != "" && strings.Trim(id, "0123456789") == ""
+}
@@ -0,0 +1,146 @@
+// Package semver implements parsing and ordering of semantic versions as
+// described in http://semver.org/. It is the same code the version utility
+// uses to decide which release of a repository is the highest one.
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Version holds all the fields of a semantic version
+type Version struct {
+	Major, Minor, Patch int
+	Special             string
+	Build               string
+}
+
+// String outputs a string version
+func (v *Version) String() string {
+	str := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Special != "" {
+		str = fmt.Sprintf("%s-%s", str, v.Special)
+	}
+	if v.Build != "" {
+		str = fmt.Sprintf("%s+%s", str, v.Build)
+	}
+
+	return str
+}
+
+// MustParse is like Parse but panics if the version cannot be parsed
+func MustParse(s string) *Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("semver: %s", err.Error()))
+	}
+	return v
+}
+
+// Compare compares version v to version w and returns 1 if v is larger,
+// -1 if w is larger and 0 if both versions have the same precedence.
+// Uses comparison rules described in http://semver.org/. Neither version
+// is modified.
+func Compare(v, w *Version) int {
+
+	// Compare release version
+	if c := compareInt(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+
+	// Pre-release versions have a lower precedence than the associated normal version.
+	if v.Special == "" && w.Special != "" {
+		return 1
+	} else if v.Special != "" && w.Special == "" {
+		return -1
+	}
+
+	// Two versions that differ only in the build metadata, have the same precedence.
+	if v.Special == w.Special {
+		return 0
+	}
+
+	// Identifiers of the special tick
+	partsv := strings.Split(v.Special, ".")
+	partsw := strings.Split(w.Special, ".")
+
+	// Compare all special tick parts
+	for i := 0; i < len(partsv) && i < len(partsw); i++ {
+		if c := compareIdentifier(partsv[i], partsw[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A larger set of pre-release fields has a higher precedence than
+	// a smaller set, if all of the preceding identifiers are equal
+	return compareInt(len(partsv), len(partsw))
+
+}
+
+// compareIdentifier compares two pre-release identifiers
+func compareIdentifier(a, b string) int {
+
+	// Numeric identifiers have lower precedence than non-numeric identifiers.
+	numa, numb := isNumeric(a), isNumeric(b)
+	if numa && !numb {
+		return -1
+	} else if !numa && numb {
+		return 1
+	}
+
+	// Compare integers numerically. Numeric identifiers have no leading
+	// zeros, so a longer identifier is a larger number (avoids overflows)
+	if numa && numb {
+		if c := compareInt(len(a), len(b)); c != 0 {
+			return c
+		}
+	}
+
+	// Compare strings lexicographically in ASCII sort order
+	return strings.Compare(a, b)
+}
+
+// compareInt compares two integers
+func compareInt(a, b int) int {
+	if a > b {
+		return 1
+	} else if a < b {
+		return -1
+	}
+	return 0
+}
+
+// Equal returns true if versions v and w have the same precedence
+func Equal(v, w *Version) bool {
+	return Compare(v, w) == 0
+}
+
+// Less returns true if version v has a lower precedence than version w
+func Less(v, w *Version) bool {
+	return Compare(v, w) < 0
+}
+
+// Versions implements the sort.Interface, ordering from the lowest
+// to the highest version
+type Versions []*Version
+
+// Len implements sort.Interface.Len
+func (vs Versions) Len() int {
+	return len(vs)
+}
+
+// Less implements sort.Interface.Less
+func (vs Versions) Less(i, j int) bool {
+	return Less(vs[i], vs[j])
+}
+
+// Swap implements sort.Interface.Swap
+func (vs Versions) Swap(i, j int) {
+	vs[i], vs[j] = vs[j], vs[i]
+}
@@ -0,0 +1,180 @@
+package semver
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		in    string
+		out   *Version
+		valid bool
+	}{
+		{"v0.0.1", &Version{Major: 0, Minor: 0, Patch: 1}, true},
+		{"v10.20.30", &Version{Major: 10, Minor: 20, Patch: 30}, true},
+		{"1.0.0", &Version{Major: 1, Minor: 0, Patch: 0}, true},
+		{"v1.0.0-rc.1", &Version{Major: 1, Minor: 0, Patch: 0, Special: "rc.1"}, true},
+		{"v1.0.0-rc.1+1504795241", &Version{Major: 1, Minor: 0, Patch: 0, Special: "rc.1", Build: "1504795241"}, true},
+		{"v1.2.3+exp.sha.5114f85", &Version{Major: 1, Minor: 2, Patch: 3, Build: "exp.sha.5114f85"}, true},
+		{"v1.0.0-RC-1.x-Y", &Version{Major: 1, Minor: 0, Patch: 0, Special: "RC-1.x-Y"}, true},
+		{"v1.0.0-0A.is.legal", &Version{Major: 1, Minor: 0, Patch: 0, Special: "0A.is.legal"}, true},
+		{"v1.0.0+0001", &Version{Major: 1, Minor: 0, Patch: 0, Build: "0001"}, true},
+		{"v1x2x3", nil, false},
+		{"v1.0", nil, false},
+		{"v01.0.0", nil, false},
+		{"v1.0.0-01", nil, false},
+		{"v1.0.0-rc..1", nil, false},
+		{"v1.0.0-", nil, false},
+		{"v1.0.0+", nil, false},
+		{"v1.0.0-rc_1", nil, false},
+		{"v99999999999999999999.0.0", nil, false},
+		{"tag: v1.0.0", nil, false},
+		{"", nil, false},
+	}
+
+	for i, test := range tests {
+		v, err := Parse(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("TestParse: test %d failed: unexpected error state: %v", i+1, err)
+			continue
+		}
+		if test.valid && *v != *test.out {
+			t.Errorf("TestParse: test %d failed: got %+v, expected %+v", i+1, v, test.out)
+		}
+	}
+
+}
+
+func TestParseError(t *testing.T) {
+
+	tests := []struct {
+		in    string
+		pos   int
+		field string
+	}{
+		{"v1x2x3", 2, "major"},
+		{"v1.02.3", 3, "minor"},
+		{"v1.2.3-rc.01", 10, "special"},
+		{"v1.2.3+build..1", 13, "build"},
+		{"v1.2.3 ", 6, ""},
+	}
+
+	for i, test := range tests {
+		_, err := Parse(test.in)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("TestParseError: test %d failed: expected *ParseError, got %v", i+1, err)
+			continue
+		}
+		if perr.Pos != test.pos || perr.Field != test.field {
+			t.Errorf("TestParseError: test %d failed: got position %d (%s), expected %d (%s)", i+1, perr.Pos, perr.Field, test.pos, test.field)
+		}
+	}
+
+}
+
+func TestString(t *testing.T) {
+
+	for i, in := range []string{"v1.2.3", "v1.2.3-rc.1", "v1.2.3+exp.sha", "v1.2.3-rc.1+exp.sha"} {
+		if out := MustParse(in).String(); out != in {
+			t.Errorf("TestString: test %d failed: got %s, expected %s", i+1, out, in)
+		}
+	}
+
+}
+
+func TestCompare(t *testing.T) {
+
+	tests := []struct {
+		v      string
+		w      string
+		result int
+	}{
+		{"v0.0.1", "v0.0.0", 1},
+		{"v0.0.0", "v0.0.1", -1},
+		{"v0.1.0", "v0.0.10", 1},
+		{"v10.1.3", "v0.10.10", 1},
+		{"v10.100.300", "v20.10.10", -1},
+		{"v1.0.0", "v1.0.0-rc1", 1},
+		{"v1.0.0", "v1.0.0-alpha.rc1", 1},
+		{"v1.0.0-alpha.rc2", "v1.0.0-beta.rc1", -1},
+		{"v1.0.0-alpha", "v1.0.0-beta.rc1", -1},
+		{"v1.0.0-beta", "v1.0.0-beta.rc1", -1},
+		{"v1.0.0-gamma", "v1.0.0-beta.rc1", 1},
+		{"v1.0.0-gamma.rc2", "v1.0.0-beta.rc3", 1},
+		{"v1.0.0-beta.1.rc2", "v1.0.0-beta.rc1.1", -1},
+		{"v1.0.0", "v1.0.1-alpha.rc1", -1},
+		{"v1.0.0-rc1", "v1.0.0", -1},
+		{"v1.0.0-rc.1+1", "v1.0.0-rc.1+2", 0},
+		{"v1.0.0-rc.1.b", "v1.0.0-rc.1.a", 1},
+		{"v1.0.0-rc-1", "v1.0.0-rc.1", 1},
+		{"v1.0.0-rc.11", "v1.0.0-rc.9", 1},
+		{"v1.0.0-rc.99999999999999999999", "v1.0.0-rc.9", 1},
+		{"v1.0.0-RC.1", "v1.0.0-rc.1", -1},
+	}
+
+	for i, test := range tests {
+		if result := Compare(MustParse(test.v), MustParse(test.w)); result != test.result {
+			t.Errorf("TestCompare: test %d failed: got %d, expected %d", i+1, result, test.result)
+		}
+	}
+
+}
+
+func TestCompareSpecOrder(t *testing.T) {
+
+	// Example from http://semver.org/#spec-item-11
+	order := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+	}
+
+	for i := 0; i < len(order)-1; i++ {
+		if Compare(MustParse(order[i]), MustParse(order[i+1])) != -1 {
+			t.Errorf("TestCompareSpecOrder: expected %s < %s", order[i], order[i+1])
+		}
+		if Compare(MustParse(order[i+1]), MustParse(order[i])) != 1 {
+			t.Errorf("TestCompareSpecOrder: expected %s > %s", order[i+1], order[i])
+		}
+	}
+
+}
+
+func TestCompareImmutable(t *testing.T) {
+
+	v := MustParse("v1.0.0-rc-1.x-y")
+	w := MustParse("v1.0.0-rc-1.x-z")
+	Compare(v, w)
+
+	if v.String() != "v1.0.0-rc-1.x-y" || w.String() != "v1.0.0-rc-1.x-z" {
+		t.Errorf("TestCompareImmutable: versions were modified: %s, %s", v.String(), w.String())
+	}
+
+}
+
+func TestVersionsSort(t *testing.T) {
+
+	versions := Versions{
+		MustParse("v1.0.0"),
+		MustParse("v0.1.0"),
+		MustParse("v1.0.0-rc.1"),
+		MustParse("v0.0.1"),
+	}
+	sort.Sort(versions)
+
+	expected := []string{"v0.0.1", "v0.1.0", "v1.0.0-rc.1", "v1.0.0"}
+	for i, v := range versions {
+		if v.String() != expected[i] {
+			t.Errorf("TestVersionsSort: position %d: got %s, expected %s", i, v.String(), expected[i])
+		}
+	}
+
+}
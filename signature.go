@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Signature states of version tags
+const (
+	SignatureUnsigned = "unsigned" // lightweight or unsigned annotated tag
+	SignatureGood     = "good"     // valid signature
+	SignatureBad      = "bad"      // invalid signature, e.g. the tag was tampered with
+	SignatureUnknown  = "unknown"  // signature cannot be checked, e.g. the key is missing
+)
+
+// Signing formats
+const (
+	SignFormatOpenPGP = "openpgp" // GPG keys
+	SignFormatSSH     = "ssh"     // SSH keys
+)
+
+// Git configuration keys holding the signing defaults of version increases
+const (
+	ConfigSign       = "version.sign"       // sign version tags (true/false)
+	ConfigSignKey    = "version.signKey"    // key used to sign version tags
+	ConfigSignFormat = "version.signFormat" // openpgp or ssh
+)
+
+// SignOptions holds the parameters of a tag signature
+type SignOptions struct {
+	Key    string // key id, or the path of an SSH key; git's user.signingKey if empty
+	Format string // openpgp or ssh; git's gpg.format if empty
+}
+
+// ParseSignFormat validates a signing format name. "gpg" is an alias of
+// openpgp
+func ParseSignFormat(name string) (string, error) {
+	switch format := strings.ToLower(name); format {
+	case "":
+		return "", nil
+	case "gpg", SignFormatOpenPGP:
+		return SignFormatOpenPGP, nil
+	case SignFormatSSH:
+		return SignFormatSSH, nil
+	}
+	return "", fmt.Errorf("unknown signing format '%s': choose openpgp or ssh", name)
+}
+
+// signOptions resolves the signature of a new version tag. Signing is
+// enabled by sign, by a key or by the repository's configuration. Returns
+// nil if the tag is not signed
+func signOptions(repo Repository, sign bool, key, format string) (*SignOptions, error) {
+
+	if !sign && key == "" {
+		value, err := repo.Config(ConfigSign)
+		if err != nil {
+			return nil, fmt.Errorf("could not read configuration: %s", err.Error())
+		}
+		if value == "" {
+			return nil, nil
+		}
+		if sign, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': expected true or false", ConfigSign, value)
+		}
+		if !sign {
+			return nil, nil
+		}
+	}
+
+	// Configured defaults
+	var err error
+	if key == "" {
+		if key, err = repo.Config(ConfigSignKey); err != nil {
+			return nil, fmt.Errorf("could not read configuration: %s", err.Error())
+		}
+	}
+	if format == "" {
+		if format, err = repo.Config(ConfigSignFormat); err != nil {
+			return nil, fmt.Errorf("could not read configuration: %s", err.Error())
+		}
+	}
+
+	format, err = ParseSignFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignOptions{Key: key, Format: format}, nil
+}
+
+// verifyVersions checks the signatures of all the versions' tags
+func verifyVersions(repo Repository, versions *Versions) {
+	for _, version := range versions.versions {
+		status, err := repo.VerifyTag(version.Tag)
+		if err != nil {
+			status = SignatureUnknown
+		}
+		version.Signature = status
+	}
+}
+
+// badSignatures lists the versions with invalid signatures
+func badSignatures(repos []string, repoVersions map[string]*Versions) []string {
+	bad := []string{}
+	for _, repo := range repos {
+		if versions, ok := repoVersions[repo]; ok {
+			for _, version := range versions.versions {
+				if version.Signature == SignatureBad {
+					bad = append(bad, fmt.Sprintf("%s in %s", version.Tag, repo))
+				}
+			}
+		}
+	}
+	return bad
+}
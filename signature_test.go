@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseSignFormat(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"", "", false},
+		{"GPG", SignFormatOpenPGP, false},
+		{"openpgp", SignFormatOpenPGP, false},
+		{"ssh", SignFormatSSH, false},
+		{"x509", "", true},
+	}
+
+	for i, test := range tests {
+		if format, err := ParseSignFormat(test.name); format != test.expected || (err != nil) != test.err {
+			t.Errorf("TestParseSignFormat: test %d failed: got '%s' (%v)", i+1, format, err)
+		}
+	}
+
+}
+
+func TestSignOptions(t *testing.T) {
+
+	tests := []struct {
+		config   map[string]string
+		sign     bool
+		key      string
+		format   string
+		expected *SignOptions
+		err      bool
+	}{
+		{nil, false, "", "", nil, false},
+		{nil, true, "", "", &SignOptions{}, false},
+		{nil, false, "ABCD", "gpg", &SignOptions{Key: "ABCD", Format: SignFormatOpenPGP}, false},
+		{map[string]string{ConfigSign: "false", ConfigSignKey: "ABCD"}, false, "", "", nil, false},
+		{map[string]string{ConfigSign: "true", ConfigSignKey: "~/.ssh/id", ConfigSignFormat: "ssh"}, false, "", "", &SignOptions{Key: "~/.ssh/id", Format: SignFormatSSH}, false},
+		{map[string]string{ConfigSignKey: "ABCD", ConfigSignFormat: "ssh"}, true, "", "openpgp", &SignOptions{Key: "ABCD", Format: SignFormatOpenPGP}, false},
+		{map[string]string{ConfigSign: "maybe"}, false, "", "", nil, true},
+		{nil, true, "", "x509", nil, true},
+	}
+
+	for i, test := range tests {
+		repo := newFakeRepository("/sign/options")
+		for key, value := range test.config {
+			repo.config[key] = value
+		}
+
+		sign, err := signOptions(repo, test.sign, test.key, test.format)
+		if (err != nil) != test.err {
+			t.Errorf("TestSignOptions: test %d failed: unexpected error %v", i+1, err)
+			continue
+		}
+		if (sign == nil) != (test.expected == nil) || sign != nil && *sign != *test.expected {
+			t.Errorf("TestSignOptions: test %d failed: got %+v", i+1, sign)
+		}
+	}
+
+}
+
+func TestIncreaseSigned(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/sign/increase")
+	repo.commit("a")
+	repo.config[ConfigSign] = "true"
+	repo.config[ConfigSignKey] = "ABCD"
+
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Yes: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseSigned: unexpected error: %s", err.Error())
+	}
+	if repo.signed["v0.0.1"] != SignatureGood || !strings.Contains(out.String(), "Tag message (signed with ABCD):") {
+		t.Errorf("TestIncreaseSigned: tag was not signed:\n%s", out.String())
+	}
+
+	plan, err := PlanIncrease(repo, &IncreaseOptions{})
+	if err == nil || plan != nil {
+		t.Errorf("TestIncreaseSigned: expected an error for a tagged HEAD")
+	}
+	repo.commit("b")
+	if plan, err = PlanIncrease(repo, &IncreaseOptions{}); err != nil || !plan.Signed || plan.SignKey != "ABCD" {
+		t.Errorf("TestIncreaseSigned: unexpected plan %+v (%v)", plan, err)
+	}
+
+}
+
+func TestListVerify(t *testing.T) {
+
+	color.NoColor = true
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "verified", ".git"), 0755); err != nil {
+		t.Fatalf("TestListVerify: could not create directory: %s", err.Error())
+	}
+
+	repo := newFakeRepository(root + "/verified")
+	repo.commit("a")
+	repo.tag("v1.0.0")
+	repo.commit("b")
+	repo.tag("v1.1.0")
+	repo.commit("c")
+	repo.tag("v1.2.0")
+	repo.signed["v1.1.0"] = SignatureGood
+	repo.signed["v1.2.0"] = SignatureUnknown
+
+	opts := &ListOptions{All: true, Backend: BackendFake, Jobs: 1, Format: FormatPlain, Verify: true}
+	list := func() (string, error) {
+		out := &bytes.Buffer{}
+		err := List(context.Background(), root, opts, out)
+		return out.String(), err
+	}
+
+	out, err := list()
+	if err != nil {
+		t.Fatalf("TestListVerify: unexpected error: %s", err.Error())
+	}
+	for _, expected := range []string{"v1.2.0  unknown", "v1.1.0  good", "v1.0.0  unsigned"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("TestListVerify: output does not contain '%s':\n%s", expected, out)
+		}
+	}
+
+	// Invalid signatures fail the listing, even if the version is not shown
+	repo.signed["v1.0.0"] = SignatureBad
+	opts.All = false
+	opts.Format = FormatTable
+	if out, err = list(); err == nil || !strings.Contains(err.Error(), "invalid signature: v1.0.0 in "+root+"/verified") {
+		t.Errorf("TestListVerify: expected an invalid signature error, got %v", err)
+	}
+	if !strings.Contains(out, "Signature") {
+		t.Errorf("TestListVerify: signature column is missing:\n%s", out)
+	}
+
+	// Signatures are not checked without --verify
+	opts.Verify = false
+	if out, err = list(); err != nil || strings.Contains(out, "Signature") {
+		t.Errorf("TestListVerify: unexpected verification (%v):\n%s", err, out)
+	}
+
+}
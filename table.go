@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// ansiEscape matches the color sequences of cells, which take no space
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// table renders rows of values in bordered columns, preceded by titles
+// and followed by footnotes
+type table struct {
+	columns   []string
+	titles    []string
+	rows      [][]string
+	footnotes []string
+}
+
+// newTable creates an empty table with a bold header of columns
+func newTable(columns ...string) *table {
+	return &table{columns: columns}
+}
+
+// addTitle adds a line above the table
+func (t *table) addTitle(title string) {
+	t.titles = append(t.titles, title)
+}
+
+// addRow adds a row of values, one per column
+func (t *table) addRow(values ...interface{}) {
+	row := make([]string, len(t.columns))
+	for i := range row {
+		if i < len(values) {
+			row[i] = fmt.Sprint(values[i])
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// addFootnote adds a line below the table
+func (t *table) addFootnote(note string) {
+	t.footnotes = append(t.footnotes, note)
+}
+
+// render writes the table. Cells are padded by their visible width, so
+// colored values stay aligned
+func (t *table) render(w io.Writer) {
+
+	width := func(s string) int {
+		return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+	}
+
+	widths := make([]int, len(t.columns))
+	for i, column := range t.columns {
+		widths[i] = width(column)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if cw := width(cell); cw > widths[i] {
+				widths[i] = cw
+			}
+		}
+	}
+
+	border := "+"
+	for _, cw := range widths {
+		border += strings.Repeat("-", cw+2) + "+"
+	}
+
+	line := func(cells []string, format func(string) string) {
+		fmt.Fprint(w, "|")
+		for i, cell := range cells {
+			fmt.Fprintf(w, " %s%s |", format(cell), strings.Repeat(" ", widths[i]-width(cell)))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	for _, title := range t.titles {
+		fmt.Fprintf(w, " %s\n", title)
+	}
+	fmt.Fprintln(w, border)
+	line(t.columns, func(s string) string { return color.New(color.Bold).Sprint(s) })
+	fmt.Fprintln(w, border)
+	for _, row := range t.rows {
+		line(row, func(s string) string { return s })
+	}
+	fmt.Fprintln(w, border)
+	for _, note := range t.footnotes {
+		fmt.Fprintf(w, " * %s\n", note)
+	}
+}
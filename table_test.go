@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestTableRender(t *testing.T) {
+
+	color.NoColor = false
+	defer func() { color.NoColor = true }()
+
+	table := newTable("Name", "Version")
+	table.addTitle("Versions")
+	table.addRow("alpha", color.New(color.FgHiBlue).Sprint("v1.0.0"))
+	table.addRow("β", "v10.0.0-rc.1")
+	table.addFootnote("Note")
+
+	out := &bytes.Buffer{}
+	table.render(out)
+
+	plain := ansiEscape.ReplaceAllString(out.String(), "")
+	expected := strings.Join([]string{
+		"",
+		" Versions",
+		"+-------+--------------+",
+		"| Name  | Version      |",
+		"+-------+--------------+",
+		"| alpha | v1.0.0       |",
+		"| β     | v10.0.0-rc.1 |",
+		"+-------+--------------+",
+		" * Note",
+		"",
+	}, "\n")
+	if plain != expected {
+		t.Errorf("TestTableRender: got\n%s\nexpected\n%s", plain, expected)
+	}
+
+}
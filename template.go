@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// ListEntry is the data a listing template is executed with. The template
+// is executed once for every listed version
+type ListEntry struct {
+	Repo    string
+	Version *Version
+	Highest bool // the version is the highest version of the repository or component
+}
+
+// IncreaseEntry is the data an increase template is executed with. The
+// template is executed once the new version is tagged
+type IncreaseEntry struct {
+	Repo    string
+	Version *Version // new version
+	Current *Version // version before the increase, v0.0.0 if there was none
+	Commit  *Commit  // tagged commit
+	Branch  string
+}
+
+// templateColors maps color names usable in templates to attributes
+var templateColors = map[string]color.Attribute{
+	"black":   color.FgHiBlack,
+	"red":     color.FgHiRed,
+	"green":   color.FgHiGreen,
+	"yellow":  color.FgHiYellow,
+	"blue":    color.FgHiBlue,
+	"magenta": color.FgHiMagenta,
+	"cyan":    color.FgHiCyan,
+	"white":   color.FgHiWhite,
+	"bold":    color.Bold,
+}
+
+// templateFuncs are the helper functions available in templates
+var templateFuncs = template.FuncMap{
+
+	// {{color "blue" .Repo}} colors a value
+	"color": func(name string, v interface{}) (string, error) {
+		attr, ok := templateColors[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown color '%s'", name)
+		}
+		return color.New(attr).Sprint(v), nil
+	},
+
+	// {{bold .Version}} makes a value bold
+	"bold": func(v interface{}) string {
+		return color.New(color.Bold).Sprint(v)
+	},
+
+	// {{pad 30 .Repo}} right-pads a value to the given width
+	"pad": func(width int, v interface{}) string {
+		return fmt.Sprintf("%-*s", width, fmt.Sprint(v))
+	},
+
+	// {{padLeft 10 .Version}} left-pads a value to the given width
+	"padLeft": func(width int, v interface{}) string {
+		return fmt.Sprintf("%*s", width, fmt.Sprint(v))
+	},
+
+	// {{date "2006-01-02" .Version.Date}} formats a date
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+
+	// {{short .Version.Commit}} abbreviates a commit hash
+	"short": shortHash,
+
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseTemplate parses a template used to render listing/increase entries.
+// A trailing newline is added unless the template ends with one
+func ParseTemplate(text string) (*template.Template, error) {
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("version").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse template: %s", err.Error())
+	}
+
+	return tmpl, nil
+}
+
+// ReadTemplate parses a template either given inline or stored in a file
+func ReadTemplate(text, file string) (*template.Template, error) {
+
+	if text != "" && file != "" {
+		return nil, fmt.Errorf("cannot use both a template and a template file")
+	}
+
+	if file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read template file: %s", err.Error())
+		}
+		text = string(content)
+	}
+
+	if text == "" {
+		return nil, nil
+	}
+
+	return ParseTemplate(text)
+}
+
+// printVersionTemplate renders every listed version with a template
+func printVersionTemplate(w io.Writer, tmpl *template.Template, repos []string, repoVersions map[string]*Versions, last bool) error {
+
+	for _, repo := range repos {
+		versions, ok := repoVersions[repo]
+		if !ok {
+			continue
+		}
+		highest := map[*Version]bool{}
+		for _, version := range versions.highest() {
+			highest[version] = true
+		}
+		for _, version := range versions.versions {
+			if last && !highest[version] {
+				continue
+			}
+			if err := tmpl.Execute(w, &ListEntry{Repo: repo, Version: version, Highest: highest[version]}); err != nil {
+				return fmt.Errorf("could not execute template: %s", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
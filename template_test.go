@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestReadTemplate(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "release.tmpl")
+	if err := os.WriteFile(file, []byte("{{.Repo}}\n"), 0644); err != nil {
+		t.Fatalf("TestReadTemplate: %s", err.Error())
+	}
+
+	tests := []struct {
+		text, file string
+		valid      bool
+		empty      bool
+	}{
+		{"{{.Repo}}", "", true, false},
+		{"", file, true, false},
+		{"", "", true, true},
+		{"{{.Repo}}", file, false, false},
+		{"", file + ".missing", false, false},
+		{"{{.Repo", "", false, false},
+		{"{{unknown .Repo}}", "", false, false},
+	}
+
+	for i, test := range tests {
+		tmpl, err := ReadTemplate(test.text, test.file)
+		if (err == nil) != test.valid {
+			t.Errorf("TestReadTemplate: test %d failed: unexpected error state: %v", i+1, err)
+			continue
+		}
+		if test.valid && (tmpl == nil) != test.empty {
+			t.Errorf("TestReadTemplate: test %d failed: unexpected template %v", i+1, tmpl)
+		}
+	}
+
+}
+
+func TestPrintVersionTemplate(t *testing.T) {
+
+	color.NoColor = true
+	repos, repoVersions := formatFixture()
+
+	tests := []struct {
+		text     string
+		last     bool
+		expected string
+	}{
+		{"{{.Repo}} {{.Version.Major}}", false, "/src/alpha 0\n/src/alpha 0\n/src/beta|gamma 2\n"},
+		{"{{.Repo}} {{.Version}}", true, "/src/alpha v0.14.1\n/src/beta|gamma v2.0.0\n"},
+		{"{{pad 16 .Repo}}|{{padLeft 8 .Version}}|{{short .Version.Commit}}\n", true, "/src/alpha      | v0.14.1|46a2962\n/src/beta|gamma |  v2.0.0|7557dd4\n"},
+		{`{{date "2006-01-02" .Version.Date}} {{color "blue" .Version.Tag}} {{bold .Version.Special}}{{if .Highest}} *{{end}}`, false, "2017-09-07 v0.14.1  *\n2017-09-07 v0.14.0-rc.1+exp rc.1\n2017-09-07 2.0.0  *\n"},
+		{`{{upper .Version.Build}}{{lower "X"}}`, false, "x\nEXPx\nx\n"},
+	}
+
+	for i, test := range tests {
+		tmpl, err := ParseTemplate(test.text)
+		if err != nil {
+			t.Fatalf("TestPrintVersionTemplate: test %d failed: %s", i+1, err.Error())
+		}
+		out := &bytes.Buffer{}
+		if err := printVersionTemplate(out, tmpl, repos, repoVersions, test.last); err != nil {
+			t.Errorf("TestPrintVersionTemplate: test %d failed: %s", i+1, err.Error())
+			continue
+		}
+		if out.String() != test.expected {
+			t.Errorf("TestPrintVersionTemplate: test %d failed: got\n%q\nexpected\n%q", i+1, out.String(), test.expected)
+		}
+	}
+
+	// Execution errors are reported
+	tmpl, _ := ParseTemplate(`{{color "purple" .Repo}}`)
+	if err := printVersionTemplate(&bytes.Buffer{}, tmpl, repos, repoVersions, true); err == nil || !strings.Contains(err.Error(), "unknown color") {
+		t.Errorf("TestPrintVersionTemplate: expected an unknown color error, got %v", err)
+	}
+
+}
+
+func TestIncreaseTemplate(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/template/increase")
+	repo.commit("first")
+	repo.tag("v1.4.2")
+	repo.commit("Fix bug#32")
+
+	tmpl, _ := ParseTemplate("Released {{.Version}} (was {{.Current}}) of {{.Repo}} on {{.Branch}}: {{.Commit.Message}} [{{short .Version.Commit}}]")
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Minor: true, Template: tmpl}, strings.NewReader("Y\n"), out); err != nil {
+		t.Fatalf("TestIncreaseTemplate: unexpected error: %s", err.Error())
+	}
+
+	expected := "Released v1.5.0 (was v1.4.2) of /template/increase on master: Fix bug#32 [" + repo.head[:7] + "]\n"
+	if !strings.HasSuffix(out.String(), expected) {
+		t.Errorf("TestIncreaseTemplate: output does not end with %q:\n%s", expected, out.String())
+	}
+
+}
@@ -2,13 +2,15 @@ package main
 
 import (
 	"fmt"
-	"github.com/fatih/color"
-	"github.com/vaitekunas/lentele"
+	"io"
 	"os"
 	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
-// printErr displays an error message
+// printErr displays an error message on stderr
 func printErr(in string, a ...interface{}) {
 	if len(a) > 0 {
 		in = fmt.Sprintf(in, a...)
@@ -16,7 +18,17 @@ func printErr(in string, a ...interface{}) {
 
 	br := color.New(color.FgHiRed)
 	b := color.New(color.FgHiRed).Add(color.Bold)
-	fmt.Printf(" %s %s\n", br.Sprint("◈"), b.Sprint(in))
+	fmt.Fprintf(os.Stderr, " %s %s\n", br.Sprint("◈"), b.Sprint(in))
+}
+
+// isInteractive checks whether in is a terminal. Readers that are not files
+// (e.g. buffers) are considered interactive
+func isInteractive(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return true
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
 
 // getRepoName formats a repo name
@@ -36,46 +48,73 @@ func getRepoName(dir string) string {
 	return fmt.Sprintf("%s%s", root, color.New(color.Bold).Sprint(repo))
 }
 
-// printVersionTable displays version data in a table
-func printVersionTable(repos []string, repoVersions map[string]*Versions, last bool) {
-
-	bold := func(v interface{}) interface{} {
-		return color.New(color.Bold).Sprint(v)
+// shortHash abbreviates a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
 	}
+	return hash
+}
+
+// signatureColors highlights the signature states of versions
+var signatureColors = map[string]*color.Color{
+	SignatureUnsigned: color.New(color.Reset),
+	SignatureGood:     color.New(color.FgHiGreen),
+	SignatureBad:      color.New(color.FgHiRed).Add(color.Bold),
+	SignatureUnknown:  color.New(color.FgHiYellow),
+}
+
+// printVersionTable displays version data in a table. Tag metadata is
+// added as columns if tagInfo is set
+func printVersionTable(w io.Writer, repos []string, repoVersions map[string]*Versions, last, tagInfo bool) {
 
-	blue := func(v interface{}) interface{} {
-		return color.New(color.FgHiBlue).Add(color.Bold).Sprint(v)
+	blue := color.New(color.FgHiBlue).Add(color.Bold)
+
+	repoName := func(aligned string) string {
+		repo := strings.TrimSpace(aligned)
+		return strings.Replace(aligned, repo, getRepoName(repo), 1)
 	}
 
-	repoName := func(v interface{}) interface{} {
-		vs, ok := v.(string)
-		if !ok {
-			return v
+	// Signature states are only known when verified, components only
+	// exist in monorepos
+	verified, components := false, false
+	for _, versions := range repoVersions {
+		for _, version := range versions.versions {
+			verified = verified || version.Signature != ""
+			components = components || version.Component != ""
 		}
-		repo := strings.TrimSpace(vs)
-		return strings.Replace(vs, repo, getRepoName(repo), 1)
 	}
 
-	table := lentele.New("Repository", "Date", "Commit", "Version")
+	columns := []string{"Repository", "Date", "Commit"}
+	if components {
+		columns = append(columns, "Component")
+	}
+	columns = append(columns, "Version")
+	if verified {
+		columns = append(columns, "Signature")
+	}
+	if tagInfo {
+		columns = append(columns, "Kind", "Tag date", "Tagger", "Message")
+	}
+
+	table := newTable(columns...)
 	if !last {
 		if len(repos) > 1 {
-			table.AddTitle("All versions per repository")
-		} else {
-			table.AddTitle(fmt.Sprintf("All versions of '%s'", repos[0]))
+			table.addTitle("All versions per repository")
+		} else if len(repos) == 1 {
+			table.addTitle(fmt.Sprintf("All versions of '%s'", repos[0]))
 		}
-		table.AddTitle("(ordered from the highest to the lowest)")
+		table.addTitle("(ordered from the highest to the lowest)")
 	} else {
 		if len(repos) > 1 {
-			table.AddTitle("Highest versions per repository")
+			table.addTitle("Highest versions per repository")
+		} else if len(repos) == 1 && components {
+			table.addTitle(fmt.Sprintf("Highest versions of '%s' and its components", repos[0]))
 		} else if len(repos) == 1 {
-			table.AddTitle(fmt.Sprintf("Highest version of '%s'", repos[0]))
+			table.addTitle(fmt.Sprintf("Highest version of '%s'", repos[0]))
 		}
 	}
 
-	if header, err := table.GetRowByName("header"); err == nil {
-		header.Modify(bold, "Repository", "Date", "Commit", "Version")
-	}
-
 	// Repository path format
 	longestRepo := 0
 	for _, repo := range repos {
@@ -103,33 +142,123 @@ func printVersionTable(repos []string, repoVersions map[string]*Versions, last b
 			continue
 		}
 
-		if last && len(versions.versions) > 0 {
-			versions.versions = versions.versions[:1]
+		highest := map[*Version]bool{}
+		for _, version := range versions.highest() {
+			highest[version] = true
 		}
 
-		for i, version := range versions.versions {
-			row := table.AddRow("")
+		for _, version := range versions.versions {
+			if last && !highest[version] {
+				continue
+			}
 			alignedRepo := fmt.Sprintf(formatRepo, repo)
 			alignedVersion := fmt.Sprintf(formatVersion, version.String())
 			if version.String() == "v0.0.0" {
 				alignedVersion = "N/A"
 			}
-			row.Insert(alignedRepo, version.Date.Format("2006-01-02 15:04"), version.Commit, alignedVersion).Modify(repoName, "Repository")
-			if i == 0 && !last {
-				row.Modify(blue, "Version")
+			date := "-"
+			if !version.Date.IsZero() {
+				date = version.Date.Format("2006-01-02 15:04")
+			}
+			if highest[version] && !last {
+				alignedVersion = blue.Sprint(alignedVersion)
+			}
+			values := []interface{}{repoName(alignedRepo), date, shortHash(version.Commit)}
+			if components && version.Component == "" {
+				values = append(values, "-")
+			} else if components {
+				values = append(values, version.Component)
+			}
+			values = append(values, alignedVersion)
+			if verified {
+				values = append(values, signatureColors[version.Signature].Sprint(version.Signature))
 			}
+			if tagInfo && version.Annotated {
+				message := firstLine(version.Message)
+				if message == "" {
+					message = "-"
+				}
+				values = append(values, TagAnnotated, version.TagDate.Format("2006-01-02 15:04"), version.Tagger, message)
+			} else if tagInfo {
+				values = append(values, TagLightweight, "-", "-", "-")
+			}
+			table.addRow(values...)
 		}
 	}
 
-	table.AddFootnote("Version order is based on the semantic versioning specification (http://semver.org/)")
-	table.AddFootnote("Commits without version tags are not shown")
+	table.addFootnote("Version order is based on the semantic versioning specification (http://semver.org/)")
+	table.addFootnote("Commits without version tags are not shown")
+	if tagInfo {
+		table.addFootnote("Lightweight tags have neither a tagger, a tag date nor a message of their own")
+		table.addFootnote("Only the first line of tag messages is shown")
+	}
+
+	for _, repo := range repos {
+		if versions, ok := repoVersions[repo]; ok {
+			for _, perr := range versions.malformed {
+				table.addFootnote(fmt.Sprintf("Ignored tag in %s: %s", repo, perr.Error()))
+			}
+			for _, version := range versions.versions {
+				if version.Signature == SignatureBad {
+					table.addFootnote(fmt.Sprintf("INVALID SIGNATURE of tag %s in %s", version.Tag, repo))
+				}
+			}
+		}
+	}
+
+	if len(table.rows) == 0 {
+		fmt.Fprint(w, "\nCould not find a single version\n\n")
+		return
+	}
+
+	table.render(w)
+	fmt.Fprintf(w, "\n")
+
+}
+
+// statusColors highlights the states of version tags compared with a remote
+var statusColors = map[string]*color.Color{
+	StatusSynced:     color.New(color.Reset),
+	StatusLocalOnly:  color.New(color.FgHiYellow),
+	StatusRemoteOnly: color.New(color.FgHiYellow),
+	StatusDiverged:   color.New(color.FgHiRed).Add(color.Bold),
+}
+
+// printStatusTable displays the differences between local and remote
+// version tags in a table
+func printStatusTable(w io.Writer, repo, remote string, statuses []*TagStatus) {
 
-	if table.GetRowCount() == 1 {
-		fmt.Println("\nCould not find a single version\n")
+	if len(statuses) == 0 {
+		fmt.Fprintf(w, "\nVersions of '%s' are in sync with '%s'\n\n", repo, remote)
 		return
 	}
 
-	table.Render(os.Stdout, false, true, false, lentele.LoadTemplate("classic"))
-	fmt.Printf("\n")
+	table := newTable("Tag", "Local", "Remote", "State")
+	table.addTitle(fmt.Sprintf("Versions of '%s' compared with '%s'", repo, remote))
+
+	longestTag := 0
+	for _, status := range statuses {
+		if len(status.Tag) > longestTag {
+			longestTag = len(status.Tag)
+		}
+	}
+	formatTag := fmt.Sprintf("%%-%ds", longestTag)
+
+	for _, status := range statuses {
+		local, upstream := shortHash(status.Local), shortHash(status.Remote)
+		if local == "" {
+			local = "-"
+		}
+		if upstream == "" {
+			upstream = "-"
+		}
+		table.addRow(fmt.Sprintf(formatTag, status.Tag), local, upstream, statusColors[status.State].Sprint(status.State))
+	}
+
+	table.addFootnote("Local-only tags were never pushed, remote-only tags were not fetched")
+	table.addFootnote("Diverged tags point to different commits locally and on the remote")
+
+	table.render(w)
+	fmt.Fprintf(w, "\n")
 
 }
@@ -2,27 +2,69 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/vaitekunas/version/semver"
 )
 
-const (
+// TieBreak decides the order of versions with the same semver precedence,
+// i.e. versions that differ only in their build metadata. Semver itself
+// considers such versions equal.
+type TieBreak string
 
-	// Regex used to parse a semver-valid version
-	V_REGEX = `v(?P<major>\d+).(?P<minor>\d+).(?P<patch>\d+)(-(?P<special>[a-z0-9\.-]+)(\+(?P<build>[a-z0-9\.-]+))?)?`
+const (
+	TieBreakNone       TieBreak = "none"        // keep git's order
+	TieBreakCommitDate TieBreak = "commit-date" // newer commit is larger
+	TieBreakTagDate    TieBreak = "tag-date"    // newer tag is larger
+	TieBreakBuild      TieBreak = "build"       // lexically larger build metadata is larger
 )
 
+// ParseTieBreak validates a tie-break strategy name
+func ParseTieBreak(name string) (TieBreak, error) {
+	switch t := TieBreak(strings.ToLower(name)); t {
+	case TieBreakNone, TieBreakCommitDate, TieBreakTagDate, TieBreakBuild:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown tie-break strategy '%s': choose none, commit-date, tag-date or build", name)
+}
+
+// Compare compares two versions of the same precedence
+func (t TieBreak) Compare(v, w *Version) int {
+
+	var a, b int64
+	switch t {
+	case TieBreakCommitDate:
+		a, b = v.Date.Unix(), w.Date.Unix()
+	case TieBreakTagDate:
+		a, b = v.TagDate.Unix(), w.TagDate.Unix()
+	case TieBreakBuild:
+		return strings.Compare(v.Build, w.Build)
+	}
+
+	if a > b {
+		return 1
+	} else if a < b {
+		return -1
+	}
+	return 0
+}
+
 // Versions implements the sort.Interface
 type Versions struct {
-	versions []*Version
+	versions  []*Version
+	malformed []*semver.ParseError
+	tiebreak  TieBreak
 }
 
 // Add adds a new version to the slice of versions
@@ -35,9 +77,14 @@ func (v *Versions) Len() int {
 	return len(v.versions)
 }
 
-// Swap implements sort.Interface.Less
+// Swap implements sort.Interface.Less. Versions of different components
+// are ordered by component name, which keeps every series together once
+// sorted in reverse
 func (v *Versions) Less(i, j int) bool {
-	return Larger(v.versions[j], v.versions[i])
+	if a, b := v.versions[i].Component, v.versions[j].Component; a != b {
+		return a > b
+	}
+	return Larger(v.versions[j], v.versions[i], v.tiebreak)
 }
 
 // Swap implements sort.Interface.Swap
@@ -47,145 +94,233 @@ func (v *Versions) Swap(i, j int) {
 	v.versions[j] = temp
 }
 
-// Version holds all the relevant details on a semantic version
-type Version struct {
-	Date                time.Time
-	Commit              string
-	Major, Minor, Patch int
-	Special             string
-	Build               string
-}
+// Component returns the versions of a single component, the repository's
+// own versions if component is empty
+func (v *Versions) Component(component string) *Versions {
 
-// String outputs a string version
-func (v *Version) String() string {
-	str := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
-	if v.Special != "" && v.Build != "" {
-		str = fmt.Sprintf("%s-%s+%s", str, v.Special, v.Build)
-	} else if v.Special != "" {
-		str = fmt.Sprintf("%s-%s", str, v.Special)
+	series := &Versions{versions: []*Version{}, malformed: v.malformed, tiebreak: v.tiebreak}
+	for _, version := range v.versions {
+		if version.Component == component {
+			series.Add(version)
+		}
 	}
 
-	return str
+	return series
 }
 
-// Larger compares version v to version w and returns true if v is larger.
-// Uses comparison rules described in http://semver.org/
-func Larger(v, w *Version) bool {
+// Match returns the versions satisfying a constraint
+func (v *Versions) Match(constraint *semver.Constraint) *Versions {
 
-	// Compare release version
-	if v.Major > w.Major {
-		return true
-	}
-	if v.Major < w.Major {
-		return false
-	}
-	if v.Major == w.Major && v.Minor > w.Minor {
-		return true
-	}
-	if v.Major == w.Major && v.Minor < w.Minor {
-		return false
-	}
-	if v.Major == w.Major && v.Minor == w.Minor && v.Patch > w.Patch {
-		return true
-	}
-	if v.Major == w.Major && v.Minor == w.Minor && v.Patch < w.Patch {
-		return false
+	matching := &Versions{versions: []*Version{}, malformed: v.malformed, tiebreak: v.tiebreak}
+	for _, version := range v.versions {
+		if constraint.Check(&version.Version) {
+			matching.Add(version)
+		}
 	}
 
-	// Replace hyphens
-	v.Special = strings.Replace(v.Special, "-", ".", -1)
-	w.Special = strings.Replace(w.Special, "-", ".", -1)
+	return matching
+}
 
-	// Pre-release versions have a lower precedence than the associated normal version.
-	if v.Special == "" && w.Special != "" {
-		return true
-	} else if v.Special != "" && w.Special == "" {
-		return false
+// highest returns the highest version of every component
+func (v *Versions) highest() []*Version {
+
+	highest := []*Version{}
+	for i, version := range v.versions {
+		if i == 0 || version.Component != v.versions[i-1].Component {
+			highest = append(highest, version)
+		}
 	}
 
-	// Two versions that differ only in the build metadata, have the same precedence.
-	// Deviation from semver rules: commit date decides precedence
-	if v.Special == w.Special {
-		if v.Date.Unix() > w.Date.Unix() {
-			return true
+	return highest
+}
+
+// Find returns the version with the given tag or normalized version, e.g.
+// both "1.2.3" and "v1.2.3" find the tag "1.2.3". Normalized versions
+// only find the repository's own versions. Returns nil if there is no
+// such version
+func (v *Versions) Find(name string) *Version {
+
+	for _, version := range v.versions {
+		if version.Tag == name {
+			return version
 		}
-		return false
 	}
 
-	// Identifiers of the special tick
-	partsv := strings.Split(v.Special, ".")
-	partsw := strings.Split(w.Special, ".")
-	splen := len(partsv)
-	if len(partsw) > splen {
-		splen = len(partsw)
+	sv, err := semver.Parse(name)
+	if err != nil {
+		return nil
+	}
+	for _, version := range v.versions {
+		if version.Component == "" && version.String() == sv.String() {
+			return version
+		}
 	}
 
-	// Compare all special tick parts
-	for i := 0; i <= splen-1; i++ {
+	return nil
+}
+
+// previous returns the highest version of the same component lower than
+// version, or the highest version if version is nil. Pre-releases are
+// skipped when looking for the predecessor of a release
+func (v *Versions) previous(version *Version) *Version {
 
-		// A larger set of pre-release fields has a higher precedence than
-		// a smaller set, if all of the preceding identifiers are equal
-		if i > len(partsw)-1 {
-			return true
+	for _, candidate := range v.versions {
+		if version == nil {
+			return candidate
 		}
-		if i > len(partsv)-1 {
-			return false
+		if candidate.Component != version.Component {
+			continue
 		}
-
-		// Numeric identifiers have lower precedence than non-numeric identifiers.
-		vint, errv := strconv.Atoi(partsv[i])
-		wint, errw := strconv.Atoi(partsw[i])
-		if errv != nil && errw == nil {
-			return true
-		} else if errv == nil && errw != nil {
-			return false
+		if version.Special == "" && candidate.Special != "" {
+			continue
 		}
-
-		// Compare integers numerically
-		if errv == nil && errw == nil {
-			if vint > wint {
-				return true
-			}
-			return false
+		if semver.Compare(&candidate.Version, &version.Version) < 0 {
+			return candidate
 		}
+	}
 
-		// Compare strings lexicographically
-		if partsv[i] > partsw[i] {
-			return true
-		} else if partsv[i] < partsw[i] {
-			return false
-		}
+	return nil
+}
+
+// Version holds a semantic version together with the tag and commit
+type Version struct {
+	semver.Version
+	Component string // tag prefix, e.g. "sub/dir" of "sub/dir/v1.2.3", see SplitTag
+	Tag       string
+	TagDate   time.Time // tagger date, the committer date for lightweight tags
+	Date      time.Time // author date of the commit
+	Commit    string
+	Annotated bool
+	Tagger    string // "Name <email>" of annotated tags
+	Message   string // annotation message of annotated tags
+
+	Signature string // signature state of the tag, only set when verified
+}
+
+// Larger compares version v to version w and returns true if v is larger.
+// Uses comparison rules described in http://semver.org/, versions with the
+// same precedence are ordered by the tie-break strategy
+func Larger(v, w *Version, tiebreak TieBreak) bool {
+
+	switch semver.Compare(&v.Version, &w.Version) {
+	case 1:
+		return true
+	case -1:
+		return false
 	}
 
-	// Should not be reached
-	return false
+	return tiebreak.Compare(v, w) > 0
 
 }
 
-// Increase increases repository's semantic version
-func Increase(major, minor, patch bool, special, build string) error {
+// IncreaseOptions holds the parameters of a version increase
+type IncreaseOptions struct {
+	Major, Minor, Patch bool
+	Special             string
+	Build               string
+	Auto                bool               // derive the tick from conventional commits
+	Message             string             // tag annotation, "Version vX.Y.Z" by default
+	Notes               bool               // append release notes to the tag annotation
+	Edit                bool               // edit the tag annotation before tagging
+	Sign                bool               // sign the tag, see also ConfigSign
+	SignKey             string             // signing key, implies Sign
+	SignFormat          string             // signing format: openpgp or ssh
+	Push                bool               // push the new tag
+	Remote              string             // remote the tag is pushed to, origin by default
+	GoMod               bool               // add the major version suffix to the Go module path if needed
+	Component           string             // monorepo component whose version is increased (optional)
+	TagScheme           string             // tag scheme of component versions: path or at, see ConfigTagScheme
+	Template            *template.Template // rendered once the version is tagged (optional)
+	Yes                 bool               // tag without asking for confirmation
+	DryRun              bool               // only print the plan, do not tag
+	Format              string             // plan format of a dry run: table (default) or json
+}
+
+// ErrAborted is returned when the user declines the version increase
+var ErrAborted = errors.New("version update aborted")
+
+// UsageError is returned when the options of an increase are invalid
+type UsageError struct {
+	Msg string
+}
+
+// Error implements the error interface
+func (e *UsageError) Error() string {
+	return e.Msg
+}
+
+// IncreasePlan describes the tag a version increase creates
+type IncreasePlan struct {
+	Schema     int       `json:"schema"`
+	Repository string    `json:"repository"`
+	Component  string    `json:"component,omitempty"`
+	Branch     string    `json:"branch"`
+	Commit     string    `json:"commit"`  // full hash of the commit to be tagged
+	Message    string    `json:"message"` // commit message
+	Author     string    `json:"author"`
+	Date       time.Time `json:"date"`    // commit date (RFC 3339)
+	Current    string    `json:"current"` // current version, empty if there is none
+	Version    string    `json:"version"` // version after the increase
+	Tag        string    `json:"tag"`     // name of the tag to be created
+	Annotation string    `json:"annotation"`
+	Level      string    `json:"level"` // applied tick: major, minor, patch or none
+
+	// Commits that drove an automatic increase
+	Commits []*ConventionalCommit `json:"commits,omitempty"`
+
+	Signed  bool   `json:"signed"`
+	SignKey string `json:"sign_key,omitempty"` // empty for git's default key
+	Remote  string `json:"remote,omitempty"`   // remote the tag is pushed to, empty if not pushed
+
+	// Go module path update committed before tagging
+	Module *ModuleRewrite `json:"module,omitempty"`
+
+	sign    *SignOptions
+	current *Version
+	version *Version
+	head    *Commit
+}
+
+// versionIncrease is the version computed by an increase, before the
+// commit to be tagged is checked
+type versionIncrease struct {
+	current  *Version  // v0.0.0 if there is none
+	version  *Version  // version after the increase, neither tagged nor committed
+	versions *Versions // versions of the component
+	commits  []*Commit // commits since the current version, only listed for automatic increases and notes
+	drivers  []*ConventionalCommit
+	level    Level
+	scheme   string
+}
+
+// nextVersion computes the version following the current version of the
+// component, see IncreaseOptions
+func nextVersion(repo Repository, opts *IncreaseOptions) (*versionIncrease, error) {
+
+	major, minor, patch := opts.Major, opts.Minor, opts.Patch
 
 	// Validate increment
 	if major && minor || major && patch || minor && patch {
-		return fmt.Errorf("cannot increase more than one level: choose major, minor or patch")
+		return nil, fmt.Errorf("cannot increase more than one level: choose major, minor or patch")
 	}
-
-	// Default increase is a patch tick
-	if !major && !minor && !patch && special == "" {
-		patch = true
+	if opts.Auto && (major || minor || patch) {
+		return nil, fmt.Errorf("cannot combine an automatic increase with major, minor or patch")
 	}
 
-	// Get pwd
-	root, err := os.Getwd()
+	scheme, err := tagScheme(repo, opts.TagScheme)
 	if err != nil {
-		return fmt.Errorf("could not determine current directory: %s", err.Error())
+		return nil, err
+	}
+	if err := validComponent(opts.Component, scheme); err != nil {
+		return nil, err
 	}
 
-	// Determine current version
-	versions, err := GetVersions(root)
+	// Determine current version of the component
+	versions, err := GetVersions(repo, TieBreakNone)
 	if err != nil {
-		return fmt.Errorf("could not determine version: %s", err.Error())
+		return nil, fmt.Errorf("could not determine version: %s", err.Error())
 	}
+	versions = versions.Component(opts.Component)
 	var current *Version
 
 	if len(versions.versions) >= 1 {
@@ -194,12 +329,53 @@ func Increase(major, minor, patch bool, special, build string) error {
 		current = &Version{}
 	}
 
+	// Commits since the current version
+	since := "the first commit"
+	var commits []*Commit
+	if opts.Auto || opts.Notes {
+		from := ""
+		if len(versions.versions) >= 1 {
+			since = current.String()
+			from = current.Commit
+		}
+
+		commits, err = repo.Commits(from, "HEAD", componentPaths(opts.Component, scheme)...)
+		if err != nil {
+			return nil, fmt.Errorf("could not list commits: %s", err.Error())
+		}
+	}
+
+	// Derive the tick from the commits
+	var drivers []*ConventionalCommit
+	if opts.Auto {
+		var level Level
+		level, drivers = AutoLevel(commits)
+		if level == LevelNone {
+			return nil, fmt.Errorf("could not derive increase: no feat, fix or breaking change commits since %s", since)
+		}
+
+		// Breaking changes of initial development versions (0.y.z) bump minor
+		if level == LevelMajor && current.Major == 0 {
+			level = LevelMinor
+		}
+
+		major, minor, patch = level == LevelMajor, level == LevelMinor, level == LevelPatch
+	}
+
+	// Default increase is a patch tick
+	if !major && !minor && !patch && opts.Special == "" {
+		patch = true
+	}
+
 	newVersion := &Version{
-		Major:   current.Major,
-		Minor:   current.Minor,
-		Patch:   current.Patch,
-		Special: special,
-		Build:   build,
+		Version: semver.Version{
+			Major:   current.Major,
+			Minor:   current.Minor,
+			Patch:   current.Patch,
+			Special: opts.Special,
+			Build:   opts.Build,
+		},
+		Component: opts.Component,
 	}
 	if major {
 		newVersion.Major++
@@ -215,24 +391,179 @@ func Increase(major, minor, patch bool, special, build string) error {
 	}
 
 	// Validate
-	if !Larger(newVersion, current) {
-		printErr("cannot apply increase: proposed version (%s) is lower than the current version (%s)", newVersion.String(), current.String())
-		os.Exit(1)
+	if _, err := semver.Parse(newVersion.String()); err != nil {
+		return nil, &UsageError{fmt.Sprintf("invalid pre-release or build metadata: %s", err.Error())}
+	}
+	if semver.Compare(&newVersion.Version, &current.Version) <= 0 {
+		return nil, fmt.Errorf("cannot apply increase: proposed version (%s) is lower than the current version (%s)", newVersion.String(), current.String())
+	}
+
+	inc := &versionIncrease{
+		current:  current,
+		version:  newVersion,
+		versions: versions,
+		commits:  commits,
+		drivers:  drivers,
+		level:    LevelNone,
+		scheme:   scheme,
+	}
+	switch {
+	case major:
+		inc.level = LevelMajor
+	case minor:
+		inc.level = LevelMinor
+	case patch:
+		inc.level = LevelPatch
+	}
+
+	return inc, nil
+}
+
+// PlanIncrease determines the new version of the repository and the commit
+// it would be tagged on. The repository is not modified
+func PlanIncrease(repo Repository, opts *IncreaseOptions) (*IncreasePlan, error) {
+
+	inc, err := nextVersion(repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	current, newVersion, versions, scheme := inc.current, inc.version, inc.versions, inc.scheme
+
+	// Go modules need a major version suffix from v2 on. Components of the
+	// path scheme are Go modules in subdirectories
+	var module *ModuleRewrite
+	if newVersion.Major != current.Major && newVersion.Major >= 2 && (opts.Component == "" || scheme == TagSchemePath) {
+		if module, err = planModuleRewrite(filepath.Join(repo.Path(), opts.Component), newVersion.Major); err != nil {
+			return nil, err
+		}
+		if module != nil && !opts.GoMod {
+			return nil, fmt.Errorf("module path %s does not match %s: use --go-mod to change it to %s", module.Path, newVersion.String(), module.NewPath)
+		}
+		// Only tags are pushed, the module path commit would not be on
+		// any remote branch
+		if module != nil && opts.Push {
+			return nil, &UsageError{"cannot push a tag on a new module path commit: tag without --push, then push the branch and the tag"}
+		}
+		if module != nil {
+			dirty, err := repo.Dirty()
+			if err != nil {
+				return nil, err
+			}
+			if dirty {
+				return nil, fmt.Errorf("cannot update module path: commit or stash the changes of the working tree first")
+			}
+		}
 	}
 
 	// Get last commit
-	ctime, commit, author, message, version, err := GetLastCommit(root)
+	head, err := repo.Head()
 	if err != nil {
-		return fmt.Errorf("could not get last commit: %s", err.Error())
+		return nil, fmt.Errorf("could not get last commit: %s", err.Error())
 	}
-	if version.String() != "v0.0.0" {
-		return fmt.Errorf("current commit already has a version: %s", version.String())
+	for _, version := range versions.versions {
+		if version.Commit == head.Hash {
+			return nil, fmt.Errorf("current commit already has a version: %s", version.String())
+		}
 	}
 
 	// Get branch
-	branch, err := GetBranch(root)
+	branch, err := repo.Branch()
+	if err != nil {
+		return nil, fmt.Errorf("could not get active branch name: %s", err.Error())
+	}
+
+	newVersion.Tag = ComponentTag(opts.Component, newVersion, scheme)
+	newVersion.Commit = head.Hash
+	newVersion.Date = head.Date
+
+	// Signature
+	sign, err := signOptions(repo, opts.Sign, opts.SignKey, opts.SignFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remote
+	remote := ""
+	if opts.Push {
+		remote = opts.Remote
+		if remote == "" {
+			remote = "origin"
+		}
+		if url, err := repo.Config("remote." + remote + ".url"); err != nil || url == "" {
+			return nil, fmt.Errorf("unknown remote '%s'", remote)
+		}
+	}
+
+	// Release notes
+	var notes *Changelog
+	if opts.Notes {
+		notes = &Changelog{Version: newVersion}
+		for _, commit := range inc.commits {
+			notes.add(commit)
+		}
+	}
+
+	plan := &IncreasePlan{
+		Schema:     SchemaVersion,
+		Repository: repo.Path(),
+		Component:  opts.Component,
+		Branch:     branch,
+		Commit:     head.Hash,
+		Message:    head.Message,
+		Author:     head.Author,
+		Date:       head.Date,
+		Version:    newVersion.String(),
+		Tag:        newVersion.Tag,
+		Annotation: tagMessage(newVersion, opts.Message, notes),
+		Level:      inc.level.String(),
+		Commits:    inc.drivers,
+		Remote:     remote,
+		Module:     module,
+		sign:       sign,
+		current:    current,
+		version:    newVersion,
+		head:       head,
+	}
+	if len(versions.versions) >= 1 {
+		plan.Current = current.String()
+	}
+	if sign != nil {
+		plan.Signed = true
+		plan.SignKey = sign.Key
+	}
+	return plan, nil
+}
+
+// Increase increases repository's semantic version. Unless opts.Yes is
+// set, the user is asked to confirm the new version on in, which must be
+// a terminal. A dry run only prints the plan. All output is written to w
+func Increase(repo Repository, opts *IncreaseOptions, in io.Reader, w io.Writer) error {
+
+	// Fail fast, when confirmation cannot be given
+	if !opts.DryRun && !opts.Yes && !isInteractive(in) {
+		return fmt.Errorf("stdin is not a terminal: use --yes to tag without confirmation")
+	}
+	if !opts.DryRun && opts.Edit && !isInteractive(in) {
+		return fmt.Errorf("stdin is not a terminal: cannot edit the tag message")
+	}
+
+	if opts.Format != "" && opts.Format != FormatTable && opts.Format != FormatJSON {
+		return fmt.Errorf("unknown plan format '%s': choose table or json", opts.Format)
+	}
+	if opts.Format == FormatJSON && !opts.DryRun {
+		return fmt.Errorf("the json format is only available with --dry-run")
+	}
+
+	plan, err := PlanIncrease(repo, opts)
 	if err != nil {
-		return fmt.Errorf("could not get active branch name: %s", err.Error())
+		return err
+	}
+
+	if opts.DryRun && opts.Format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(plan)
 	}
 
 	// Formatting functions
@@ -245,53 +576,181 @@ func Increase(major, minor, patch bool, special, build string) error {
 		if len(a) > 0 {
 			s = fmt.Sprintf(s, a...)
 		}
-		fmt.Printf("\t %s  %s\n", bullet(), s)
+		fmt.Fprintf(w, "\t %s  %s\n", bullet(), s)
 	}
 
 	// Print information
-	fmt.Println("")
-
-	fmt.Println("Repository:")
-	out(getRepoName(root))
-	fmt.Println("")
-
-	fmt.Println("Commit to be tagged as the new version:")
-	out("Branch:\t%s", bold(branch))
-	out("Message:\t%s", bold(message))
-	out("Hash:\t%s", bold(commit))
-	out("Date:\t%s", bold(ctime.Format("2006-01-02 15:04:06")))
-	out("Author:\t%s", bold(author))
-	fmt.Println("")
-
-	fmt.Println("Version increment:")
-	if current.String() != "v0.0.0" {
-		out("Current version: %s", bold(current.String()))
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "Repository:")
+	out(getRepoName(plan.Repository))
+	if plan.Component != "" {
+		out("Component: %s", bold(plan.Component))
+	}
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "Commit to be tagged as the new version:")
+	out("Branch:\t%s", bold(plan.Branch))
+	out("Message:\t%s", bold(plan.Message))
+	out("Hash:\t%s", bold(plan.Commit))
+	out("Date:\t%s", bold(plan.Date.Format("2006-01-02 15:04:06")))
+	out("Author:\t%s", bold(plan.Author))
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "Version increment:")
+	if plan.Current != "" {
+		out("Current version: %s", bold(plan.Current))
 	} else {
 		out("Current version: %s", bold("none"))
 	}
-	out("Proposed version after increase: %s", bold(newVersion.String()))
+	out("Proposed version after increase: %s", bold(plan.Version))
+	if plan.Tag != plan.Version {
+		out("Tag: %s", bold(plan.Tag))
+	}
+	if plan.Remote != "" {
+		out("Tag pushed to: %s", bold(plan.Remote))
+	}
+
+	if plan.Module != nil {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Module path update, committed before tagging:")
+		out("%s -> %s", plan.Module.Path, bold(plan.Module.NewPath))
+		for _, file := range plan.Module.Files {
+			out("%s", file)
+		}
+	}
 
-	fmt.Println("")
-	fmt.Printf("%s", bold("Tag new version? [Y/n] (default: n): "))
-	reader := bufio.NewReader(os.Stdin)
-	text, _ := reader.ReadString('\n')
-	if text != "Y\n" {
-		fmt.Println(abort("\nVersion update aborted\n"))
+	if len(plan.Commits) > 0 {
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "Automatic %s increase, derived from:\n", plan.Level)
+		for _, commit := range plan.Commits {
+			out("%s\t%s", shortHash(commit.Commit), bold(commit.String()))
+		}
+		if plan.Commits[0].Breaking && plan.Level == LevelMinor.String() {
+			fmt.Fprintln(w, "Breaking changes increase the minor version while the major version is 0")
+		}
+	}
+
+	fmt.Fprintln(w, "")
+	if plan.Signed && plan.SignKey != "" {
+		fmt.Fprintf(w, "Tag message (signed with %s):\n", plan.SignKey)
+	} else if plan.Signed {
+		fmt.Fprintln(w, "Tag message (signed):")
+	} else {
+		fmt.Fprintln(w, "Tag message:")
+	}
+	for _, line := range strings.Split(plan.Annotation, "\n") {
+		if line == "" {
+			fmt.Fprintln(w, "")
+			continue
+		}
+		fmt.Fprintf(w, "\t    %s\n", line)
+	}
+
+	fmt.Fprintln(w, "")
+	if opts.DryRun {
+		if plan.Module != nil {
+			fmt.Fprintf(w, "Dry run: tag %s would be created on a new commit on top of %s\n\n", bold(plan.Tag), bold(plan.Commit))
+			return nil
+		}
+		fmt.Fprintf(w, "Dry run: tag %s would be created on commit %s\n\n", bold(plan.Tag), bold(plan.Commit))
 		return nil
 	}
 
+	if !opts.Yes {
+		fmt.Fprintf(w, "%s", bold("Tag new version? [Y/n] (default: n): "))
+		reader := bufio.NewReader(in)
+		text, _ := reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(text)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(w, abort("\nVersion update aborted\n"))
+			return ErrAborted
+		}
+	}
+
+	// Edit the annotation
+	if opts.Edit {
+		message, err := editMessage(plan.Annotation, plan.Tag)
+		if err != nil {
+			return err
+		}
+		if message == "" {
+			fmt.Fprintln(w, abort("\nEmpty tag message, version update aborted\n"))
+			return ErrAborted
+		}
+		plan.Annotation = message
+	}
+
+	// Update the module path in a commit of its own, which is tagged instead
+	if plan.Module != nil {
+		if err := plan.Module.Apply(); err != nil {
+			return err
+		}
+		commit, err := repo.CommitFiles(fmt.Sprintf("chore: update module path to %s", plan.Module.NewPath), plan.Module.Paths())
+		if err != nil {
+			if rerr := plan.Module.Restore(); rerr != nil {
+				return fmt.Errorf("could not commit module path update: %s (%s)", err.Error(), rerr.Error())
+			}
+			return fmt.Errorf("could not commit module path update: %s", err.Error())
+		}
+		plan.Commit, plan.head = commit.Hash, commit
+		plan.version.Commit, plan.version.Date = commit.Hash, commit.Date
+		fmt.Fprintf(w, "\nModule path updated to %s in commit %s\n", bold(plan.Module.NewPath), bold(shortHash(commit.Hash)))
+	}
+
 	// Apply tag
-	if err := exec.Command("git", "tag", "-a", newVersion.String(), "-m", fmt.Sprintf(`"Version %s"`, newVersion.String()), commit).Run(); err != nil {
+	if err := repo.CreateTag(plan.Tag, plan.Annotation, plan.Commit, plan.sign); err != nil {
 		return fmt.Errorf("could not apply tag: %s", err.Error())
 	}
 
-	fmt.Println(success("\nVersion updated\n"))
+	// Push the new tag only. A rejected tag is deleted, so that the
+	// increase can be repeated
+	if plan.Remote != "" {
+		if err := repo.PushTag(plan.Remote, plan.Tag); err != nil {
+			if derr := repo.DeleteTag(plan.Tag); derr != nil {
+				return fmt.Errorf("could not push tag %s to %s: %s; the local tag could not be deleted either: %s", plan.Tag, plan.Remote, err.Error(), derr.Error())
+			}
+			fmt.Fprintln(w, abort(fmt.Sprintf("\nPush to %s rejected, local tag %s deleted\n", plan.Remote, plan.Tag)))
+			return fmt.Errorf("could not push tag %s to %s: %s", plan.Tag, plan.Remote, err.Error())
+		}
+	}
+
+	fmt.Fprintln(w, success("\nVersion updated\n"))
+	if plan.Remote != "" {
+		fmt.Fprintf(w, "Tag %s pushed to %s\n\n", bold(plan.Tag), bold(plan.Remote))
+	}
+
+	if opts.Template != nil {
+		entry := &IncreaseEntry{
+			Repo:    plan.Repository,
+			Version: plan.version,
+			Current: plan.current,
+			Commit:  plan.head,
+			Branch:  plan.Branch,
+		}
+		if err := opts.Template.Execute(w, entry); err != nil {
+			return fmt.Errorf("could not execute template: %s", err.Error())
+		}
+	}
 
 	return nil
 }
 
-// List lists all version of all repositories starting with root path
-func List(root string, all bool) error {
+// ListOptions holds the parameters of a version listing
+type ListOptions struct {
+	All      bool
+	TieBreak TieBreak
+	Backend  string
+	Jobs     int                // number of repositories scanned concurrently
+	Progress io.Writer          // scanning progress is reported here (optional)
+	Format   string             // output format, see ParseFormat
+	Template *template.Template // renders every version, overrides Format (optional)
+	Verify   bool               // check the signatures of version tags
+	TagInfo  bool               // show the kind, tagger, date and message of version tags
+}
+
+// List lists all version of all repositories starting with root path.
+// Scanning stops when ctx is cancelled
+func List(ctx context.Context, root string, opts *ListOptions, w io.Writer) error {
 
 	if root == "" {
 		dir, err := os.Getwd()
@@ -303,37 +762,33 @@ func List(root string, all bool) error {
 		return fmt.Errorf("provided root path is not a directory")
 	}
 
-	repos := []string{}
-	repoVersions := map[string]*Versions{}
-
-	var scan func(string)
-	scan = func(dir string) {
-		files, err := ioutil.ReadDir(dir)
-		if err != nil {
-			return
-		}
-
-		for _, file := range files {
-			name := file.Name()
-			if file.IsDir() {
-				if name == ".git" {
-					v, errv := GetVersions(dir)
-					if errv != nil {
-						continue
-					}
-					repos = append(repos, dir)
-					repoVersions[dir] = v
-				} else if name[:1] != "." {
-					scan(fmt.Sprintf("%s/%s", dir, name))
-				}
-			}
-		}
+	repoVersions, err := ScanVersions(ctx, FindRepositories(root), opts)
+	if err != nil {
+		return err
 	}
 
-	scan(root)
+	repos := []string{}
+	for repo := range repoVersions {
+		repos = append(repos, repo)
+	}
 
 	sort.Strings(repos)
-	printVersionTable(repos, repoVersions, !all)
+
+	// Invalid signatures fail the listing, even if they are not listed
+	bad := badSignatures(repos, repoVersions)
+
+	if opts.Template != nil {
+		err = printVersionTemplate(w, opts.Template, repos, repoVersions, !opts.All)
+	} else {
+		err = printVersions(w, opts.Format, repos, repoVersions, !opts.All, opts.TagInfo)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(bad) > 0 {
+		return fmt.Errorf("invalid signature: %s", strings.Join(bad, ", "))
+	}
 
 	return nil
 }
@@ -1,37 +1,504 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/vaitekunas/version/semver"
 )
 
 func TestLarger(t *testing.T) {
 
+	version := func(s string, date int64) *Version {
+		return &Version{Version: *semver.MustParse(s), Date: time.Unix(date, 0), TagDate: time.Unix(100-date, 0)}
+	}
+
 	tests := []struct {
-		v      *Version
-		w      *Version
-		larger bool
+		v        *Version
+		w        *Version
+		tiebreak TieBreak
+		larger   bool
 	}{
-		{&Version{Major: 0, Minor: 0, Patch: 1}, &Version{Major: 0, Minor: 0, Patch: 0}, true},
-		{&Version{Major: 0, Minor: 0, Patch: 0}, &Version{Major: 0, Minor: 0, Patch: 1}, false},
-		{&Version{Major: 0, Minor: 1, Patch: 0}, &Version{Major: 0, Minor: 0, Patch: 10}, true},
-		{&Version{Major: 10, Minor: 1, Patch: 3}, &Version{Major: 0, Minor: 10, Patch: 10}, true},
-		{&Version{Major: 10, Minor: 100, Patch: 300}, &Version{Major: 20, Minor: 10, Patch: 10}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "rc1"}, true},
-		{&Version{Major: 1, Minor: 0, Patch: 0}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "alpha.rc1"}, true},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "alpha.rc2"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc1"}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "alpha"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc1"}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "beta"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc1"}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "gamma"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc1"}, true},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "gamma.rc2"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc3"}, true},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.1.rc2"}, &Version{Major: 1, Minor: 0, Patch: 0, Special: "beta.rc1.1"}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0}, &Version{Major: 1, Minor: 0, Patch: 1, Special: "alpha.rc1"}, false},
-		{&Version{Major: 1, Minor: 0, Patch: 0, Special: "rc1"}, &Version{Major: 1, Minor: 0, Patch: 0}, false},
+		{version("v0.0.1", 0), version("v0.0.0", 0), TieBreakNone, true},
+		{version("v0.0.0", 0), version("v0.0.1", 0), TieBreakNone, false},
+		{version("v0.1.0", 0), version("v0.0.10", 0), TieBreakNone, true},
+		{version("v10.1.3", 0), version("v0.10.10", 0), TieBreakNone, true},
+		{version("v10.100.300", 0), version("v20.10.10", 0), TieBreakNone, false},
+		{version("v1.0.0", 0), version("v1.0.0-rc1", 0), TieBreakNone, true},
+		{version("v1.0.0", 0), version("v1.0.0-alpha.rc1", 0), TieBreakNone, true},
+		{version("v1.0.0-alpha.rc2", 0), version("v1.0.0-beta.rc1", 0), TieBreakNone, false},
+		{version("v1.0.0-alpha", 0), version("v1.0.0-beta.rc1", 0), TieBreakNone, false},
+		{version("v1.0.0-beta", 0), version("v1.0.0-beta.rc1", 0), TieBreakNone, false},
+		{version("v1.0.0-gamma", 0), version("v1.0.0-beta.rc1", 0), TieBreakNone, true},
+		{version("v1.0.0-gamma.rc2", 0), version("v1.0.0-beta.rc3", 0), TieBreakNone, true},
+		{version("v1.0.0-beta.1.rc2", 0), version("v1.0.0-beta.rc1.1", 0), TieBreakNone, false},
+		{version("v1.0.0", 0), version("v1.0.1-alpha.rc1", 0), TieBreakNone, false},
+		{version("v1.0.0-rc1", 0), version("v1.0.0", 0), TieBreakNone, false},
+		{version("v1.0.0-rc1+2", 20), version("v1.0.0-rc1+1", 10), TieBreakNone, false},
+		{version("v1.0.0-rc1+1", 10), version("v1.0.0-rc1+2", 20), TieBreakNone, false},
+		{version("v1.0.0-rc1+2", 20), version("v1.0.0-rc1+1", 10), TieBreakCommitDate, true},
+		{version("v1.0.0-rc1+2", 10), version("v1.0.0-rc1+1", 20), TieBreakCommitDate, false},
+		{version("v1.0.0-rc1+2", 20), version("v1.0.0-rc1+1", 10), TieBreakTagDate, false},
+		{version("v1.0.0-rc1+2", 10), version("v1.0.0-rc1+1", 20), TieBreakTagDate, true},
+		{version("v1.0.0-rc1+2", 10), version("v1.0.0-rc1+1", 20), TieBreakBuild, true},
+		{version("v1.0.0-rc1+1", 20), version("v1.0.0-rc1+2", 10), TieBreakBuild, false},
+		{version("v1.0.0-rc2+1", 0), version("v1.0.0-rc1+2", 20), TieBreakCommitDate, true},
 	}
 
 	for i, test := range tests {
-		if Larger(test.v, test.w) != test.larger {
+		if Larger(test.v, test.w, test.tiebreak) != test.larger {
 			t.Errorf("TestLarger: test %d failed", i+1)
 		}
 	}
 
 }
+
+func TestParseTagRefs(t *testing.T) {
+
+	ref := func(fields ...string) string { return strings.Join(fields, "\x00") + "\x00\n" }
+	signature := "-----BEGIN PGP SIGNATURE-----\nxyz\n-----END PGP SIGNATURE-----\n"
+	out := strings.Join([]string{
+		ref("refs/tags/v1.0.0-rc.2", "commit", "aaa", "100", "", "", "", "100", "", "", "Commit subject", ""),
+		ref("refs/tags/v1.0.0", "tag", "bbb", "", "commit", "aaa", "100", "200", "Tester", "<tester@example.com>", "Version v1.0.0\nMulti\n\nline\n"+signature, signature),
+		ref("refs/tags/sub/dir/v0.1.0", "commit", "ccc", "300", "", "", "", "300", "", "", "", ""),
+		ref("refs/tags/tree", "tree", "ddd", "", "", "", "", "", "", "", "", ""),
+		ref("refs/tags/nested", "tag", "eee", "", "tag", "bbb", "", "400", "Tester", "<tester@example.com>", "", ""),
+		ref("refs/heads/release-v2.0.0", "commit", "fff", "500", "", "", "", "500", "", "", "", ""),
+	}, "")
+
+	expected := []string{
+		"v1.0.0-rc.2 aaa 100 100 false  ",
+		"v1.0.0 aaa 100 200 true Tester <tester@example.com> Version v1.0.0\nMulti\n\nline",
+		"sub/dir/v0.1.0 ccc 300 300 false  ",
+	}
+	tags := parseTagRefs(out)
+	if len(tags) != len(expected) {
+		t.Fatalf("TestParseTagRefs: expected %d tags, got %d", len(expected), len(tags))
+	}
+	for i, tag := range tags {
+		got := fmt.Sprintf("%s %s %d %d %t %s %s", tag.Name, tag.Commit, tag.Date.Unix(), tag.TagDate.Unix(), tag.Annotated, tag.Tagger, tag.Message)
+		if got != expected[i] {
+			t.Errorf("TestParseTagRefs: test %d failed: got '%s', expected '%s'", i+1, got, expected[i])
+		}
+	}
+
+}
+
+func TestNewVersion(t *testing.T) {
+
+	tests := []struct {
+		tag     string
+		version string
+		valid   bool
+	}{
+		{"v1.0.0", "v1.0.0", true},
+		{"v1.0.0-RC.1+Build.7", "v1.0.0-RC.1+Build.7", true},
+		{"2.0.0", "v2.0.0", true},
+		{"latest", "", true},
+		{"release-v2.0.0", "", true},
+		{"v01.0.0", "", false},
+		{"v1x2x3", "", false},
+	}
+
+	for i, test := range tests {
+		v, err := NewVersion(&Tag{Name: test.tag, Commit: "abc1234"})
+		if (err == nil) != test.valid {
+			t.Errorf("TestNewVersion: test %d failed: unexpected error state: %v", i+1, err)
+			continue
+		}
+		if test.version == "" && v != nil {
+			t.Errorf("TestNewVersion: test %d failed: expected no version, got %s", i+1, v.String())
+		} else if test.version != "" && (v == nil || v.String() != test.version) {
+			t.Errorf("TestNewVersion: test %d failed: expected %s, got %v", i+1, test.version, v)
+		}
+	}
+
+}
+
+func TestIncrease(t *testing.T) {
+
+	color.NoColor = true
+
+	tests := []struct {
+		setup   func(repo *fakeRepository)
+		opts    *IncreaseOptions
+		input   string
+		tag     string
+		err     string
+		outputs []string
+	}{
+		// Default patch tick on an untagged repository
+		{func(repo *fakeRepository) { repo.commit("initial") }, &IncreaseOptions{}, "Y\n", "v0.0.1", "", []string{"Current version: none", "Proposed version after increase: v0.0.1", "Version updated"}},
+
+		// Major/minor/patch ticks
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("b") }, &IncreaseOptions{Patch: true}, "Y\n", "v0.14.2", "", []string{"Current version: v0.14.1", "Message:\tb"}},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("b") }, &IncreaseOptions{Minor: true}, "Y\n", "v0.15.0", "", nil},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("b") }, &IncreaseOptions{Major: true}, "Y\n", "v1.0.0", "", nil},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v0.14.1"); repo.commit("b") }, &IncreaseOptions{Major: true, Special: "rc.1", Build: "1504795241"}, "Y\n", "v1.0.0-rc.1+1504795241", "", nil},
+
+		// Highest version is chosen regardless of tag order
+		{func(repo *fakeRepository) {
+			repo.commit("a")
+			repo.tag("v1.0.0")
+			repo.commit("b")
+			repo.tag("v0.9.0", "latest")
+			repo.commit("c")
+		}, &IncreaseOptions{}, "Y\n", "v1.0.1", "", []string{"Current version: v1.0.0"}},
+
+		// Release of a pre-release version
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.0.0-rc.1"); repo.commit("b") }, &IncreaseOptions{Special: "rc.2"}, "Y\n", "v1.0.0-rc.2", "", nil},
+
+		// Branch is reported
+		{func(repo *fakeRepository) { repo.commit("a"); repo.checkout("hotfix"); repo.commit("b") }, &IncreaseOptions{}, "Y\n", "v0.0.1", "", []string{"Branch:\thotfix"}},
+
+		// Confirmation
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{}, "y\n", "v0.0.1", "", []string{"Tag new version?"}},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{}, " yes\r\n", "v0.0.1", "", nil},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Yes: true}, "", "v0.0.1", "", []string{"Version updated"}},
+
+		// Aborted by the user
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{}, "n\n", "", "version update aborted", []string{"Version update aborted"}},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{}, "", "", "version update aborted", []string{"Version update aborted"}},
+
+		// Automatic increase from conventional commits since the current version
+		{func(repo *fakeRepository) {
+			repo.commit("feat!: old breaking change")
+			repo.tag("v1.4.2")
+			repo.commit("fix: a")
+			repo.commit("feat(cli): b")
+			repo.commit("docs: c")
+		}, &IncreaseOptions{Auto: true}, "Y\n", "v1.5.0", "", []string{"Automatic minor increase, derived from:", "feat(cli): b"}},
+		{func(repo *fakeRepository) {
+			repo.commit("feat: a")
+			repo.tag("v1.4.2")
+			repo.commit("fix: b\n\nBREAKING CHANGE: c")
+		}, &IncreaseOptions{Auto: true}, "Y\n", "v2.0.0", "", []string{"Automatic major increase", "fix!: b"}},
+		{func(repo *fakeRepository) { repo.commit("feat: a"); repo.tag("v0.3.1"); repo.commit("feat!: b") }, &IncreaseOptions{Auto: true}, "Y\n", "v0.4.0", "", []string{"Automatic minor increase", "while the major version is 0"}},
+		{func(repo *fakeRepository) { repo.commit("fix: a"); repo.commit("chore: b") }, &IncreaseOptions{Auto: true, Special: "rc.1"}, "Y\n", "v0.0.1-rc.1", "", nil},
+
+		// Commits of components only consider their directory
+		{func(repo *fakeRepository) {
+			repo.commit("feat: a", "api/api.go", "web/web.go")
+			repo.tag("api/v1.2.0", "web/v1.0.0")
+			repo.commit("feat(web)!: b", "web/web.go")
+			repo.commit("fix(api): c", "api/api.go")
+			repo.commit("docs: d", "Readme.md")
+		}, &IncreaseOptions{Auto: true, Component: "api"}, "Y\n", "api/v1.2.1", "", []string{"Automatic patch increase", "fix(api): c"}},
+
+		// Components of the at scheme are names, not directories
+		{func(repo *fakeRepository) {
+			repo.commit("feat: a", "services/api.go")
+			repo.tag("api@1.2.0")
+			repo.commit("feat(api): b", "services/api.go")
+		}, &IncreaseOptions{Auto: true, Notes: true, Component: "api", TagScheme: TagSchemeAt}, "Y\n", "api@1.3.0", "", []string{"Automatic minor increase", "feat(api): b", "- api: b"}},
+
+		// Errors
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Major: true, Minor: true}, "Y\n", "", "cannot increase more than one level", nil},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.0.0"); repo.commit("b") }, &IncreaseOptions{Special: "rc.1"}, "Y\n", "", "lower than the current version", nil},
+		{func(repo *fakeRepository) { repo.commit("a"); repo.tag("v1.0.0") }, &IncreaseOptions{}, "Y\n", "", "current commit already has a version: v1.0.0", nil},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Auto: true, Minor: true}, "Y\n", "", "cannot combine an automatic increase", nil},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Minor: true, Special: "rc_1!"}, "Y\n", "", "invalid pre-release or build metadata", nil},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Special: "01", Build: "a..b"}, "Y\n", "", "invalid pre-release or build metadata", nil},
+		{func(repo *fakeRepository) { repo.commit("a") }, &IncreaseOptions{Special: "rc 1", DryRun: true}, "", "", "invalid pre-release or build metadata", nil},
+		{func(repo *fakeRepository) { repo.commit("feat: a"); repo.tag("v1.0.0"); repo.commit("docs: b") }, &IncreaseOptions{Auto: true}, "Y\n", "", "no feat, fix or breaking change commits since v1.0.0", nil},
+	}
+
+	for i, test := range tests {
+
+		repo := newFakeRepository(fmt.Sprintf("/increase/%d", i+1))
+		test.setup(repo)
+		tagCount := len(repo.tags)
+
+		out := &bytes.Buffer{}
+		err := Increase(repo, test.opts, strings.NewReader(test.input), out)
+
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("TestIncrease: test %d failed: expected error '%s', got %v", i+1, test.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("TestIncrease: test %d failed: unexpected error: %s", i+1, err.Error())
+		}
+
+		if test.tag == "" {
+			if len(repo.tags) != tagCount {
+				t.Errorf("TestIncrease: test %d failed: unexpected tag %s", i+1, repo.tags[len(repo.tags)-1].Name)
+			}
+		} else if len(repo.tags) != tagCount+1 || repo.tags[tagCount].Name != test.tag || repo.tags[tagCount].Commit != repo.head {
+			t.Errorf("TestIncrease: test %d failed: expected tag %s on HEAD", i+1, test.tag)
+		}
+
+		for _, expected := range test.outputs {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("TestIncrease: test %d failed: output does not contain '%s':\n%s", i+1, expected, out.String())
+			}
+		}
+	}
+
+}
+
+func TestList(t *testing.T) {
+
+	color.NoColor = true
+
+	root := t.TempDir()
+	for _, dir := range []string{"alpha/.git", "nested/beta/.git", "empty/.git", "plain", ".hidden/gamma/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("TestList: could not create directory: %s", err.Error())
+		}
+	}
+
+	alpha := newFakeRepository(root + "/alpha")
+	alpha.commit("a")
+	alpha.tag("v0.1.0")
+	alpha.commit("b")
+	alpha.tag("v0.2.0-rc.1", "v01.0.0")
+	alpha.commit("c")
+	alpha.tag("v0.2.0")
+
+	beta := newFakeRepository(root + "/nested/beta")
+	beta.commit("a")
+	beta.tag("v1.0.0")
+
+	newFakeRepository(root + "/empty").commit("a")
+	gamma := newFakeRepository(root + "/.hidden/gamma")
+	gamma.commit("a")
+	gamma.tag("v9.9.9")
+
+	tests := []struct {
+		all      bool
+		expected []string
+		missing  []string
+	}{
+		{false, []string{"Highest versions per repository", "v0.2.0 ", "v1.0.0", "alpha", "nested/beta", "v01.0.0"}, []string{"v0.1.0", "v0.2.0-rc.1", "gamma", "v9.9.9"}},
+		{true, []string{"All versions per repository", "v0.1.0", "v0.2.0-rc.1", "v0.2.0 ", "v1.0.0"}, []string{"gamma"}},
+	}
+
+	for i, test := range tests {
+		out := &bytes.Buffer{}
+		if err := List(context.Background(), root, &ListOptions{All: test.all, TieBreak: TieBreakCommitDate, Backend: BackendFake, Jobs: 4}, out); err != nil {
+			t.Errorf("TestList: test %d failed: unexpected error: %s", i+1, err.Error())
+			continue
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("TestList: test %d failed: output does not contain '%s':\n%s", i+1, expected, out.String())
+			}
+		}
+		for _, missing := range test.missing {
+			if strings.Contains(out.String(), missing) {
+				t.Errorf("TestList: test %d failed: output contains '%s':\n%s", i+1, missing, out.String())
+			}
+		}
+	}
+
+	// Ordering of all versions: from the highest to the lowest
+	out := &bytes.Buffer{}
+	if err := List(context.Background(), root+"/alpha", &ListOptions{All: true, TieBreak: TieBreakCommitDate, Backend: BackendFake}, out); err != nil {
+		t.Fatalf("TestList: unexpected error: %s", err.Error())
+	}
+	idx := []int{}
+	for _, v := range []string{"v0.2.0 ", "v0.2.0-rc.1", "v0.1.0"} {
+		idx = append(idx, strings.Index(out.String(), v))
+	}
+	if idx[0] < 0 || idx[0] > idx[1] || idx[1] > idx[2] {
+		t.Errorf("TestList: versions are not ordered from the highest to the lowest:\n%s", out.String())
+	}
+
+	// Invalid root
+	if err := List(context.Background(), root+"/missing", &ListOptions{Backend: BackendFake}, &bytes.Buffer{}); err == nil {
+		t.Errorf("TestList: expected an error for a missing root directory")
+	}
+
+}
+
+func TestScanVersions(t *testing.T) {
+
+	dirs := []string{}
+	for i := 0; i < 50; i++ {
+		dir := fmt.Sprintf("/scan/%02d", i)
+		repo := newFakeRepository(dir)
+		repo.commit("a")
+		repo.tag(fmt.Sprintf("v%d.0.0", i))
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, "/scan/missing")
+
+	// Results do not depend on the number of workers
+	for _, jobs := range []int{0, 1, 3, 8, 100} {
+		progress := &bytes.Buffer{}
+		repoVersions, err := ScanVersions(context.Background(), dirs, &ListOptions{Backend: BackendFake, Jobs: jobs, Progress: progress})
+		if err != nil {
+			t.Errorf("TestScanVersions: %d jobs: unexpected error: %s", jobs, err.Error())
+			continue
+		}
+		if len(repoVersions) != 50 {
+			t.Errorf("TestScanVersions: %d jobs: expected 50 repositories, got %d", jobs, len(repoVersions))
+		}
+		for i := 0; i < 50; i++ {
+			versions, ok := repoVersions[dirs[i]]
+			if !ok || len(versions.versions) != 1 || versions.versions[0].String() != fmt.Sprintf("v%d.0.0", i) {
+				t.Errorf("TestScanVersions: %d jobs: unexpected versions of %s", jobs, dirs[i])
+			}
+		}
+		if !strings.Contains(progress.String(), "Scanned 51/51 repositories") {
+			t.Errorf("TestScanVersions: %d jobs: unexpected progress '%s'", jobs, progress.String())
+		}
+	}
+
+	// Cancelled scans fail
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ScanVersions(ctx, dirs, &ListOptions{Backend: BackendFake, Jobs: 4}); err == nil {
+		t.Errorf("TestScanVersions: expected an error for a cancelled scan")
+	}
+
+}
+
+func TestIncreaseNonInteractive(t *testing.T) {
+
+	repo := newFakeRepository("/increase/non-interactive")
+	repo.commit("a")
+
+	// Pipes are not terminals
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("TestIncreaseNonInteractive: %s", err.Error())
+	}
+	defer r.Close()
+	w.Write([]byte("Y\n"))
+	w.Close()
+
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{}, r, out); err == nil || !strings.Contains(err.Error(), "not a terminal") {
+		t.Errorf("TestIncreaseNonInteractive: expected a terminal error, got %v", err)
+	}
+	if len(repo.tags) != 0 || out.Len() != 0 {
+		t.Errorf("TestIncreaseNonInteractive: increase did not fail fast")
+	}
+
+	// Confirmation is not needed with --yes
+	if err := Increase(repo, &IncreaseOptions{Yes: true}, r, out); err != nil {
+		t.Errorf("TestIncreaseNonInteractive: unexpected error: %s", err.Error())
+	}
+	if len(repo.tags) != 1 || repo.tags[0].Name != "v0.0.1" {
+		t.Errorf("TestIncreaseNonInteractive: version was not tagged")
+	}
+
+	// Aborts are distinguishable from failures
+	repo.commit("b")
+	if err := Increase(repo, &IncreaseOptions{}, strings.NewReader("n\n"), out); err != ErrAborted {
+		t.Errorf("TestIncreaseNonInteractive: expected ErrAborted, got %v", err)
+	}
+
+}
+
+func TestIncreaseDryRun(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/increase/dry-run")
+	repo.commit("a")
+	repo.tag("v0.14.1")
+	repo.commit("Fix bug#32")
+
+	// No confirmation is needed and nothing is tagged
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Minor: true, DryRun: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseDryRun: unexpected error: %s", err.Error())
+	}
+	if len(repo.tags) != 1 {
+		t.Errorf("TestIncreaseDryRun: repository was tagged")
+	}
+	for _, expected := range []string{"Proposed version after increase: v0.15.0", "Dry run: tag v0.15.0 would be created on commit " + repo.head} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("TestIncreaseDryRun: output does not contain '%s':\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Tag new version?") {
+		t.Errorf("TestIncreaseDryRun: dry run asked for confirmation")
+	}
+
+	// JSON plan
+	out.Reset()
+	if err := Increase(repo, &IncreaseOptions{Minor: true, DryRun: true, Format: FormatJSON}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreaseDryRun: unexpected error: %s", err.Error())
+	}
+	plan := &IncreasePlan{}
+	if err := json.Unmarshal(out.Bytes(), plan); err != nil {
+		t.Fatalf("TestIncreaseDryRun: invalid JSON: %s", err.Error())
+	}
+	if plan.Schema != SchemaVersion || plan.Tag != "v0.15.0" || plan.Commit != repo.head || plan.Current != "v0.14.1" || plan.Branch != "master" || plan.Message != "Fix bug#32" {
+		t.Errorf("TestIncreaseDryRun: unexpected plan: %s", out.String())
+	}
+
+	// Validation still applies
+	if err := Increase(repo, &IncreaseOptions{Special: "rc.1", DryRun: true}, strings.NewReader(""), out); err == nil {
+		t.Errorf("TestIncreaseDryRun: expected a validation error")
+	}
+
+	// JSON plans require a dry run
+	if err := Increase(repo, &IncreaseOptions{Yes: true, Format: FormatJSON}, strings.NewReader(""), out); err == nil {
+		t.Errorf("TestIncreaseDryRun: expected an error for a JSON increase without --dry-run")
+	}
+	if len(repo.tags) != 1 {
+		t.Errorf("TestIncreaseDryRun: repository was tagged")
+	}
+
+}
+
+func TestIncreasePush(t *testing.T) {
+
+	color.NoColor = true
+
+	repo := newFakeRepository("/increase/push")
+	repo.commit("a")
+	repo.remotes["upstream"] = map[string]string{}
+
+	// Only the new tag is pushed
+	out := &bytes.Buffer{}
+	if err := Increase(repo, &IncreaseOptions{Yes: true, Push: true}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreasePush: unexpected error: %s", err.Error())
+	}
+	if len(repo.remotes["origin"]) != 1 || repo.remotes["origin"]["v0.0.1"] != repo.head || !strings.Contains(out.String(), "Tag v0.0.1 pushed to origin") {
+		t.Errorf("TestIncreasePush: tag was not pushed to origin:\n%s", out.String())
+	}
+
+	// Other remotes
+	repo.commit("b")
+	if err := Increase(repo, &IncreaseOptions{Yes: true, Push: true, Remote: "upstream"}, strings.NewReader(""), out); err != nil {
+		t.Fatalf("TestIncreasePush: unexpected error: %s", err.Error())
+	}
+	if len(repo.remotes["upstream"]) != 1 || len(repo.remotes["origin"]) != 1 {
+		t.Errorf("TestIncreasePush: tag was not pushed to upstream")
+	}
+
+	// Unknown remotes fail before tagging
+	repo.commit("c")
+	if err := Increase(repo, &IncreaseOptions{Yes: true, Push: true, Remote: "fork"}, strings.NewReader(""), out); err == nil || !strings.Contains(err.Error(), "unknown remote 'fork'") {
+		t.Errorf("TestIncreasePush: expected an unknown remote error, got %v", err)
+	}
+	if len(repo.tags) != 2 {
+		t.Errorf("TestIncreasePush: repository was tagged")
+	}
+
+	// Rejected tags are deleted locally
+	repo.remotes["origin"]["v0.0.3"] = "0000000"
+	out.Reset()
+	err := Increase(repo, &IncreaseOptions{Yes: true, Push: true}, strings.NewReader(""), out)
+	if err == nil || !strings.Contains(err.Error(), "could not push tag v0.0.3 to origin") {
+		t.Errorf("TestIncreasePush: expected a push error, got %v", err)
+	}
+	if len(repo.tags) != 2 || !strings.Contains(out.String(), "Push to origin rejected, local tag v0.0.3 deleted") || strings.Contains(out.String(), "Version updated") {
+		t.Errorf("TestIncreasePush: rejected tag was not rolled back:\n%s", out.String())
+	}
+
+}
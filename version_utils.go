@@ -1,53 +1,49 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"regexp"
+	"io/ioutil"
 	"sort"
-	"strconv"
 	"strings"
-	"time"
-)
+	"sync"
 
-// GetVersions returns all the versions from committed tags
-func GetVersions(dir string) (*Versions, error) {
+	"github.com/vaitekunas/version/semver"
+)
 
-	// Change dir to repo root
-	if err := os.Chdir(dir); err != nil {
-		return nil, fmt.Errorf("could not change path to '%s': %s", dir, err.Error())
-	}
+// GetVersions returns all the versions from committed tags, ordered from
+// the highest to the lowest version
+func GetVersions(repo Repository, tiebreak TieBreak) (*Versions, error) {
 
 	// Get all tags
-	cmd := exec.Command("git", "log", "--tags", `--pretty="%h\t%at\t%D"`)
-	out, err := cmd.Output()
+	tags, err := repo.Tags()
 	if err != nil {
 		return nil, fmt.Errorf("could not list versions: %s", err.Error())
 	}
 
-	// Find newest version
-	versions := &Versions{versions: []*Version{}}
-	for _, line := range strings.Split(string(out), "\n") {
+	return newVersions(tags, tiebreak), nil
 
-		// Remove quotes
-		line = strings.TrimSpace(line)
-		line = strings.Trim(line, `"`)
+}
 
-		// Verify correct output
-		vparts := strings.Split(line, `\t`)
-		if len(vparts) != 3 {
-			continue
-		}
+// newVersions extracts the versions from tags, ordered from the highest to
+// the lowest version
+func newVersions(tags []*Tag, tiebreak TieBreak) *Versions {
+
+	// Find newest version
+	versions := &Versions{versions: []*Version{}, tiebreak: tiebreak}
+	for _, tag := range tags {
 
 		// Extract version
-		v, err := ExtractVersion(vparts[0], vparts[1], vparts[2])
-		if err != nil {
+		v, err := NewVersion(tag)
+		if perr, ok := err.(*semver.ParseError); ok {
+			versions.malformed = append(versions.malformed, perr)
+			continue
+		} else if err != nil {
 			continue
 		}
 
-		// Ignore commits without tags
-		if v.String() == "v0.0.0" {
+		// Ignore tags that are not versions
+		if v == nil {
 			continue
 		}
 
@@ -57,132 +53,155 @@ func GetVersions(dir string) (*Versions, error) {
 	}
 
 	// Sort with newest version being first
-	sort.Sort(sort.Reverse(versions))
+	sort.Stable(sort.Reverse(versions))
 
-	return versions, nil
+	return versions
 
 }
 
-// ExtractVersion extracts the version from git output
-func ExtractVersion(commitPart, timePart, tagPart string) (*Version, error) {
+// FindRepositories recursively finds all repositories in root. Hidden
+// directories are skipped
+func FindRepositories(root string) []string {
 
-	// Parse UNIX timestamp
-	tint, err := strconv.ParseInt(timePart, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse string to UNIX timestamp")
-	}
-	timestamp := time.Unix(tint, 0)
-
-	// Initiate new version
-	v := &Version{
-		Commit: commitPart,
-		Date:   timestamp,
-	}
-
-	// Match and parse version fields
-	re := regexp.MustCompile(V_REGEX)
-	match := re.FindStringSubmatch(tagPart)
-	for i, name := range re.SubexpNames() {
+	repos := []string{}
 
-		if i == 0 || len(match) < i+1 {
-			continue
+	var scan func(string)
+	scan = func(dir string) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return
 		}
 
-		// Attempt field conversion to int
-		vi, erri := strconv.Atoi(match[i])
-
-		// Fill the version struct
-		switch name {
-
-		case "major":
-			if erri != nil {
-				return nil, fmt.Errorf("error parsing major tick")
-			}
-			v.Major = vi
-
-		case "minor":
-			if erri != nil {
-				return nil, fmt.Errorf("error parsing minor tick")
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() {
+				if name == ".git" {
+					repos = append(repos, dir)
+				} else if name[:1] != "." {
+					scan(fmt.Sprintf("%s/%s", dir, name))
+				}
 			}
-			v.Minor = vi
-
-		case "patch":
-			if erri != nil {
-				return nil, fmt.Errorf("error parsing patch tick")
-			}
-			v.Patch = vi
-
-		case "special":
-			v.Special = match[i]
-
-		case "build":
-			v.Build = match[i]
-
 		}
 	}
 
-	return v, nil
+	scan(root)
+
+	return repos
 }
 
-// GetLastCommit returns current commit
-func GetLastCommit(root string) (date time.Time, commit, author, message string, version *Version, err error) {
+// ScanVersions gets the versions of multiple repositories concurrently,
+// using a pool of opts.Jobs workers. Repositories that cannot be read
+// are skipped. Returns an error if ctx is cancelled before all the
+// repositories are scanned
+func ScanVersions(ctx context.Context, dirs []string, opts *ListOptions) (map[string]*Versions, error) {
 
-	// Change dir to repo root
-	if err := os.Chdir(root); err != nil {
-		return time.Now(), "", "", "", nil, fmt.Errorf("could not change path to '%s': %s", root, err.Error())
+	type result struct {
+		dir      string
+		versions *Versions
 	}
 
-	// Get last log
-	cmd := exec.Command("git", "log", "-1", `--pretty="%H\t%at\t%an\t%d\t%s"`)
-	out, err := cmd.Output()
-	if err != nil {
-		return time.Now(), "", "", "", nil, fmt.Errorf("could not get last commit: %s", err.Error())
+	workers := opts.Jobs
+	if workers < 1 {
+		workers = 1
 	}
 
-	// Cleanup
-	outStr := strings.TrimSpace(string(out))
-	outStr = strings.Trim(outStr, `"`)
-	parts := strings.Split(outStr, `\t`)
+	jobs := make(chan string)
+	results := make(chan result)
+
+	// Workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range jobs {
+				res := result{dir: dir}
+				if repo, err := OpenRepository(dir, opts.Backend); err == nil {
+					res.versions, _ = GetVersions(repo, opts.TieBreak)
+					if res.versions != nil && opts.Verify {
+						verifyVersions(repo, res.versions)
+					}
+				}
+				results <- res
+			}
+		}()
+	}
+
+	// Feed repositories until done or cancelled
+	go func() {
+		defer close(jobs)
+		for _, dir := range dirs {
+			select {
+			case jobs <- dir:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Collect results in the order of completion
+	repoVersions := map[string]*Versions{}
+	scanned := 0
+	for res := range results {
+		scanned++
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "\rScanned %d/%d repositories", scanned, len(dirs))
+		}
+		if res.versions != nil {
+			repoVersions[res.dir] = res.versions
+		}
+	}
 
-	if len(parts) != 5 {
-		return time.Now(), "", "", "", nil, fmt.Errorf("invalid git output")
+	if opts.Progress != nil && len(dirs) > 0 {
+		fmt.Fprintf(opts.Progress, "\n")
 	}
 
-	// Extract version
-	v, err := ExtractVersion(parts[0], parts[1], parts[3])
-	if err != nil {
-		return time.Now(), "", "", "", nil, fmt.Errorf("could not extract version")
+	if ctx.Err() != nil && scanned < len(dirs) {
+		return nil, fmt.Errorf("scanning interrupted after %d/%d repositories", scanned, len(dirs))
 	}
 
-	return v.Date, parts[0], parts[2], parts[4], v, nil
+	return repoVersions, nil
 }
 
-// GetBranch returns current branch
-func GetBranch(root string) (string, error) {
+// NewVersion creates a version from a tag, optionally prefixed by a
+// component (see SplitTag). Tags that do not look like versions are
+// ignored (nil is returned), tags that look like versions, but do not
+// follow the semver specification, are reported
+func NewVersion(tag *Tag) (*Version, error) {
 
-	// Change dir to repo root
-	if err := os.Chdir(root); err != nil {
-		return "", fmt.Errorf("could not change path to '%s': %s", root, err.Error())
-	}
-
-	// Get last log
-	cmd := exec.Command("git", "branch", "--all")
-	out, err := cmd.Output()
+	component, name := SplitTag(tag.Name)
+	sv, err := semver.Parse(name)
 	if err != nil {
-		return "", fmt.Errorf("could not get branches: %s", err.Error())
-	}
-
-	// Find active branch
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		line = strings.Trim(line, `"`)
-		if strings.HasPrefix(line, "*") {
-			if len(line) < 3 {
-				return "", fmt.Errorf("invalid branch name")
+		if looksLikeVersion(name) {
+			if perr, ok := err.(*semver.ParseError); ok {
+				perr.Input, perr.Pos = tag.Name, perr.Pos+len(tag.Name)-len(name)
 			}
-			return line[2:], nil
+			return nil, err
 		}
+		return nil, nil
 	}
 
-	return "", fmt.Errorf("could not determine active branch")
+	return &Version{
+		Version:   *sv,
+		Component: component,
+		Tag:       tag.Name,
+		TagDate:   tag.TagDate,
+		Date:      tag.Date,
+		Commit:    tag.Commit,
+		Annotated: tag.Annotated,
+		Tagger:    tag.Tagger,
+		Message:   tag.Message,
+	}, nil
+}
+
+// looksLikeVersion checks whether a tag was meant to be a version, i.e.
+// starts with a digit, optionally prefixed by a "v"
+func looksLikeVersion(tag string) bool {
+	tag = strings.TrimPrefix(tag, "v")
+	return tag != "" && tag[0] >= '0' && tag[0] <= '9'
 }